@@ -8,16 +8,57 @@ import (
 	"syscall"
 
 	"github.com/P1llus/genlog"
+	"github.com/P1llus/genlog/pkg/config"
 )
 
 func main() {
+	// Subcommands come before the flags of log generation
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "validate":
+			os.Exit(runValidate(os.Args[2:], os.Stdout, os.Stderr))
+		case "preview":
+			os.Exit(runPreview(os.Args[2:], os.Stdout, os.Stderr))
+		}
+	}
+
 	// Parse command line arguments
 	configFile := flag.String("config", "config.yaml", "Path to the configuration file")
 	count := flag.Int("count", 1000, "Number of logs to generate (0 for infinite)")
+	rate := flag.Float64("rate", 0, "Maximum events per second across all outputs, overrides the config (0 for unlimited)")
+	duration := flag.Duration("duration", 0, "Stop after this long, e.g. 10m, overrides the config. Unless -count is given, count is then unlimited")
 	flag.Parse()
 
-	// Create generator from config file
-	gen, err := genlog.NewFromFile(*configFile, *count)
+	cfg, err := config.ReadConfig(*configFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Only override the configured values when the flags were given
+	flagsSet := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) {
+		flagsSet[f.Name] = true
+	})
+	if flagsSet["rate"] {
+		cfg.Rate = *rate
+	}
+	if flagsSet["duration"] {
+		cfg.Duration = *duration
+	}
+	// Time based runs shouldn't end at the default count
+	if cfg.Duration > 0 && !flagsSet["count"] {
+		*count = 0
+	}
+
+	// Keep stdout clean for the generated logs when they are piped to another program
+	status := os.Stdout
+	if cfg.WritesToStdout() {
+		status = os.Stderr
+	}
+
+	// Create generator from the config
+	gen, err := genlog.NewFromConfig(cfg, *count)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error creating generator: %v\n", err)
 		os.Exit(1)
@@ -28,23 +69,29 @@ func main() {
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 
 	// Start the generator
-	fmt.Printf("Starting log generation... (count: %d)\n", *count)
+	fmt.Fprintf(status, "Starting log generation... (count: %d, duration: %s)\n", *count, cfg.Duration)
 	gen.Start()
 
-	// Wait for either interrupt signal or completion
-	if *count > 0 {
-		fmt.Printf("Waiting for %d logs to be generated...\n", *count)
-		// Wait for either signal or completion
-		select {
-		case <-sigChan:
-			fmt.Println("\nReceived interrupt signal, shutting down gracefully...")
-		case <-gen.Done():
-			fmt.Printf("\nSuccessfully generated %d logs!\n", *count)
+	// Report how generation ends
+	if *count > 0 || cfg.Duration > 0 {
+		if *count > 0 {
+			fmt.Fprintf(status, "Waiting for %d logs to be generated...\n", *count)
+		}
+		if cfg.Duration > 0 {
+			fmt.Fprintf(status, "Generating logs for %s...\n", cfg.Duration)
 		}
 	} else {
-		fmt.Println("Generating logs indefinitely. Press Ctrl+C to stop.")
-		<-sigChan
-		fmt.Println("\nShutting down gracefully...")
+		fmt.Fprintln(status, "Generating logs indefinitely. Press Ctrl+C to stop.")
+	}
+
+	// Wait for either signal or completion. Generation also completes
+	// early when a line fails to render, which Stop reports.
+	finished := false
+	select {
+	case <-sigChan:
+		fmt.Fprintln(status, "\nReceived interrupt signal, shutting down gracefully...")
+	case <-gen.Done():
+		finished = true
 	}
 
 	// Stop the generator
@@ -53,5 +100,13 @@ func main() {
 		os.Exit(1)
 	}
 
-	fmt.Println("Log generation stopped successfully")
+	if finished {
+		if cfg.Duration > 0 {
+			fmt.Fprintln(status, "\nLog generation finished!")
+		} else {
+			fmt.Fprintf(status, "\nSuccessfully generated %d logs!\n", *count)
+		}
+	}
+
+	fmt.Fprintln(status, "Log generation stopped successfully")
 }
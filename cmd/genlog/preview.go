@@ -0,0 +1,67 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/P1llus/genlog"
+	"github.com/P1llus/genlog/pkg/config"
+)
+
+// runPreview implements genlog preview, which prints rendered lines to stdout
+// without initializing the outputs of the configuration. It returns the exit code.
+func runPreview(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("preview", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	configFile := flags.String("config", "config.yaml", "Path to the configuration file")
+	count := flags.Int("count", 10, "Number of lines to print")
+	templateIdx := flags.Int("template", -1, "Only render the template with this index, counting from 0")
+	showTemplate := flags.Bool("show-template", false, "Prefix every line with the index and name of its template")
+	seed := flags.Uint64("seed", 0, "Seed for repeatable previews, overrides the config")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: genlog preview [flags]")
+		fmt.Fprintln(stderr, "\nPrints rendered lines to stdout without writing to the configured outputs.")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+	if *count < 0 {
+		fmt.Fprintf(stderr, "invalid value %d for flag -count: must not be negative\n", *count)
+		flags.Usage()
+		return 2
+	}
+
+	cfg, err := config.ReadConfig(*configFile)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error loading config: %v\n", err)
+		return 1
+	}
+	flags.Visit(func(f *flag.Flag) {
+		if f.Name == "seed" {
+			cfg.Seed = *seed
+		}
+	})
+
+	lines, err := genlog.Preview(cfg, *count, *templateIdx)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error previewing: %v\n", err)
+		return 1
+	}
+	for _, line := range lines {
+		if *showTemplate {
+			label := fmt.Sprint(line.Template)
+			if line.Name != "" {
+				label += " " + line.Name
+			}
+			fmt.Fprintf(stdout, "[%s] ", label)
+		}
+		fmt.Fprintln(stdout, line.Line)
+	}
+	return 0
+}
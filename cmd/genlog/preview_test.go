@@ -0,0 +1,135 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunPreview(t *testing.T) {
+	valid := writeConfig(t, validConfig)
+	invalid := writeConfig(t, strings.Replace(validConfig, "{{level}}", "{{levle}}", 1))
+
+	tests := []struct {
+		name       string
+		args       []string
+		wantCode   int
+		wantLines  int
+		wantStdout string
+		wantStderr string
+	}{
+		{
+			name:      "valid config",
+			args:      []string{"-config", valid, "-count", "3"},
+			wantCode:  0,
+			wantLines: 3,
+		},
+		{
+			name:       "show template",
+			args:       []string{"-config", valid, "-count", "1", "-template", "0", "-show-template"},
+			wantCode:   0,
+			wantLines:  1,
+			wantStdout: "[0] ",
+		},
+		{
+			name:      "no lines",
+			args:      []string{"-config", valid, "-count", "0"},
+			wantCode:  0,
+			wantLines: 0,
+		},
+		{
+			name:       "invalid template",
+			args:       []string{"-config", invalid},
+			wantCode:   1,
+			wantStderr: `Error previewing: template 0: line 1, column 3: function "levle" not defined, did you mean "level"?`,
+		},
+		{
+			name:       "template out of range",
+			args:       []string{"-config", valid, "-template", "1"},
+			wantCode:   1,
+			wantStderr: "Error previewing: template 1 does not exist, the config has 1 templates",
+		},
+		{
+			name:       "missing file",
+			args:       []string{"-config", filepath.Join(t.TempDir(), "missing.yaml")},
+			wantCode:   1,
+			wantStderr: "Error loading config:",
+		},
+		{
+			name:       "negative count",
+			args:       []string{"-config", valid, "-count", "-1"},
+			wantCode:   2,
+			wantStderr: "invalid value -1 for flag -count: must not be negative",
+		},
+		{
+			name:       "unknown flag",
+			args:       []string{"-nope"},
+			wantCode:   2,
+			wantStderr: "flag provided but not defined: -nope",
+		},
+		{
+			name:       "help",
+			args:       []string{"-h"},
+			wantCode:   0,
+			wantStderr: "Usage: genlog preview",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			if code := runPreview(tt.args, &stdout, &stderr); code != tt.wantCode {
+				t.Errorf("runPreview() = %d, want %d, stderr: %s", code, tt.wantCode, stderr.String())
+			}
+			if lines := strings.Count(stdout.String(), "\n"); lines != tt.wantLines {
+				t.Errorf("Got %d lines, want %d: %q", lines, tt.wantLines, stdout.String())
+			}
+			if !strings.HasPrefix(stdout.String(), tt.wantStdout) {
+				t.Errorf("stdout = %q, want prefix %q", stdout.String(), tt.wantStdout)
+			}
+			if !strings.Contains(stderr.String(), tt.wantStderr) {
+				t.Errorf("stderr = %q, want it to contain %q", stderr.String(), tt.wantStderr)
+			}
+		})
+	}
+}
+
+func TestRunPreviewSeed(t *testing.T) {
+	file := writeConfig(t, validConfig)
+
+	preview := func(seed string) string {
+		t.Helper()
+		var stdout, stderr bytes.Buffer
+		if code := runPreview([]string{"-config", file, "-count", "20", "-seed", seed}, &stdout, &stderr); code != 0 {
+			t.Fatalf("runPreview() = %d, want 0, stderr: %s", code, stderr.String())
+		}
+		return stdout.String()
+	}
+
+	tests := []struct {
+		name     string
+		seed     string
+		wantSame bool
+	}{
+		{
+			name:     "same seed",
+			seed:     "42",
+			wantSame: true,
+		},
+		{
+			name:     "different seed",
+			seed:     "43",
+			wantSame: false,
+		},
+	}
+
+	want := preview("42")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := preview(tt.seed); (got == want) != tt.wantSame {
+				t.Errorf("Output with seed %s equal to seed 42 = %v, want %v:\n%s", tt.seed, got == want, tt.wantSame, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/P1llus/genlog"
+	"github.com/P1llus/genlog/pkg/config"
+)
+
+// runValidate implements genlog validate, which checks configuration files
+// without generating logs. It returns the exit code: 0 when every file is
+// valid, 1 when any is invalid and 2 for invalid arguments.
+func runValidate(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("validate", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	configFile := flags.String("config", "config.yaml", "Path to the configuration file, used when no files are given")
+	samples := flags.Int("samples", 3, "Number of lines to render from every template")
+	quiet := flags.Bool("quiet", false, "Only report invalid files")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: genlog validate [flags] [config.yaml ...]")
+		fmt.Fprintln(stderr, "\nChecks the configuration files, their outputs and templates, without generating logs.")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+
+	files := flags.Args()
+	if len(files) == 0 {
+		files = []string{*configFile}
+	}
+
+	code := 0
+	for _, file := range files {
+		errs := validateFile(file, *samples)
+		if len(errs) == 0 {
+			if !*quiet {
+				fmt.Fprintf(stdout, "%s: ok\n", file)
+			}
+			continue
+		}
+		code = 1
+		for _, err := range errs {
+			fmt.Fprintf(stderr, "%s: %v\n", file, err)
+		}
+	}
+	return code
+}
+
+// validateFile loads and validates a configuration file, returning every error found
+func validateFile(file string, samples int) []error {
+	cfg, err := config.ReadConfig(file)
+	if err != nil {
+		return []error{err}
+	}
+	err = genlog.Validate(cfg, samples)
+	if err == nil {
+		return nil
+	}
+	// Validate joins the errors of every template
+	var joined interface{ Unwrap() []error }
+	if errors.As(err, &joined) {
+		return joined.Unwrap()
+	}
+	return []error{err}
+}
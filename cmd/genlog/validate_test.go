@@ -0,0 +1,120 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const validConfig = `
+templates:
+  - template: "{{level}} user {{Username}} logged in"
+    weight: 1
+custom_types:
+  level:
+    - INFO
+    - WARN
+outputs:
+  - type: stdout
+`
+
+// writeConfig writes a configuration file to a temporary directory and returns its path
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRunValidate(t *testing.T) {
+	valid := writeConfig(t, validConfig)
+	invalid := writeConfig(t, strings.Replace(validConfig, "{{level}}", "{{levle}}", 1))
+	twoInvalid := writeConfig(t, strings.NewReplacer(
+		"{{level}}", "{{levle}}",
+		"custom_types:", "  - template: \"{{Usernme}}\"\n    weight: 1\ncustom_types:",
+	).Replace(validConfig))
+	noOutputs := writeConfig(t, strings.Split(validConfig, "outputs:")[0])
+
+	tests := []struct {
+		name       string
+		args       []string
+		wantCode   int
+		wantStdout string
+		wantStderr string
+	}{
+		{
+			name:       "valid config",
+			args:       []string{"-config", valid},
+			wantCode:   0,
+			wantStdout: valid + ": ok\n",
+		},
+		{
+			name:     "valid config quiet",
+			args:     []string{"-quiet", valid},
+			wantCode: 0,
+		},
+		{
+			name:       "invalid template",
+			args:       []string{valid, invalid},
+			wantCode:   1,
+			wantStdout: valid + ": ok\n",
+			wantStderr: invalid + `: template 0: line 1, column 3: function "levle" not defined, did you mean "level"?`,
+		},
+		{
+			name:     "two invalid templates",
+			args:     []string{twoInvalid},
+			wantCode: 1,
+			wantStderr: twoInvalid + `: template 0: line 1, column 3: function "levle" not defined, did you mean "level"?` + "\n" +
+				twoInvalid + `: template 1: line 1, column 3: function "Usernme" not defined`,
+		},
+		{
+			name:       "no outputs",
+			args:       []string{noOutputs},
+			wantCode:   1,
+			wantStderr: noOutputs + ": error validating config: no outputs configured",
+		},
+		{
+			name:       "missing file",
+			args:       []string{filepath.Join(t.TempDir(), "missing.yaml")},
+			wantCode:   1,
+			wantStderr: "no such file or directory",
+		},
+		{
+			name:       "unknown flag",
+			args:       []string{"-nope"},
+			wantCode:   2,
+			wantStderr: "flag provided but not defined: -nope",
+		},
+		{
+			name:       "invalid flag value",
+			args:       []string{"-samples", "many"},
+			wantCode:   2,
+			wantStderr: `invalid value "many" for flag -samples`,
+		},
+		{
+			name:       "help",
+			args:       []string{"-h"},
+			wantCode:   0,
+			wantStderr: "Usage: genlog validate",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			if code := runValidate(tt.args, &stdout, &stderr); code != tt.wantCode {
+				t.Errorf("runValidate() = %d, want %d, stderr: %s", code, tt.wantCode, stderr.String())
+			}
+			if stdout.String() != tt.wantStdout {
+				t.Errorf("stdout = %q, want %q", stdout.String(), tt.wantStdout)
+			}
+			if !strings.Contains(stderr.String(), tt.wantStderr) {
+				t.Errorf("stderr = %q, want it to contain %q", stderr.String(), tt.wantStderr)
+			}
+		})
+	}
+}
@@ -15,9 +15,12 @@ import (
 type Generator interface {
 	// Start begins generating and sending logs
 	Start()
-	// Stop gracefully stops the generator
+	// Stop gracefully stops the generator. It returns the error that ended
+	// generation early, such as a template that fails to render.
 	Stop() error
-	// Done returns a channel that is closed when the generator has completed
+	// Done returns a channel that is closed when the generator has completed,
+	// after the requested count, the configured Duration, a call to Stop or
+	// an error generating a line
 	Done() chan struct{}
 	// GenerateLogLine generates a single log line using a randomly selected template
 	GenerateLogLine() (string, error)
@@ -46,12 +49,45 @@ func NewFromFile(configPath string, maxCount int) (Generator, error) {
 	return NewFromConfig(cfg, maxCount)
 }
 
+// Renderer renders log lines from the templates of a config in memory,
+// without outputs. Use GenerateLogLine or GenerateEvent to render lines.
+type Renderer = generator.Renderer
+
+// NewRenderer creates a renderer for the templates of a config. Outputs are
+// not required and not initialized. count is the number of lines a backfill
+// clock spreads its timestamps over, and may be 0 for other clocks.
+func NewRenderer(cfg *config.Config, count int) (*Renderer, error) {
+	renderer, err := generator.NewRenderer(cfg, count)
+	if err != nil {
+		return nil, fmt.Errorf("error creating renderer: %w", err)
+	}
+	return renderer, nil
+}
+
+// Validate checks a config without initializing its outputs, and renders
+// samples lines from every template to find errors that only show up while
+// rendering. A samples of 0 renders generator.DefaultSamples lines.
+func Validate(cfg *config.Config, samples int) error {
+	return generator.Validate(cfg, samples)
+}
+
+// PreviewLine is a line rendered by Preview, with the template that produced it
+type PreviewLine = generator.PreviewLine
+
+// Preview renders count lines without initializing the outputs of a config.
+// With a template index of 0 or more, every line is rendered from that
+// template, otherwise templates are selected by weight.
+func Preview(cfg *config.Config, count, templateIdx int) ([]PreviewLine, error) {
+	return generator.Preview(cfg, count, templateIdx)
+}
+
 // Start begins generating and sending logs
 func (g *GeneratorStruct) Start() {
 	g.gen.Start()
 }
 
-// Stop gracefully stops the generator
+// Stop gracefully stops the generator and returns the error that ended
+// generation early, if any
 func (g *GeneratorStruct) Stop() error {
 	return g.gen.Stop()
 }
@@ -78,6 +114,41 @@ type LogTemplate = config.LogTemplate
 // OutputConfig represents a single output configuration
 type OutputConfig = config.OutputConfig
 
+// CustomType represents a custom type with weighted values or a numeric distribution
+type CustomType = config.CustomType
+
+// CustomValue represents a possible value of a custom type with its selection weight
+type CustomValue = config.CustomValue
+
+// Distribution represents the numeric distribution of a custom type
+type Distribution = config.Distribution
+
+// TemplateField represents a named field of a template defined as fields
+type TemplateField = config.TemplateField
+
+// TemplateFields represents the ordered fields of a template
+type TemplateFields = config.TemplateFields
+
+// EncoderConfig represents how an output encodes templates defined as fields
+type EncoderConfig = config.EncoderConfig
+
+// ClockConfig represents how the timestamps of generated lines are chosen
+type ClockConfig = config.ClockConfig
+
+// ClockMode represents the mode of the clock
+type ClockMode = config.ClockMode
+
+const (
+	// ClockModeRandom picks a random time for every line
+	ClockModeRandom = config.ClockModeRandom
+	// ClockModeRealtime uses the current time of every line
+	ClockModeRealtime = config.ClockModeRealtime
+	// ClockModeSimulated runs a clock from a start time at a configurable speed
+	ClockModeSimulated = config.ClockModeSimulated
+	// ClockModeBackfill spreads the lines over a time range
+	ClockModeBackfill = config.ClockModeBackfill
+)
+
 // OutputType represents the type of output destination for logs
 type OutputType = config.OutputType
 
@@ -86,4 +157,52 @@ const (
 	OutputTypeFile = config.OutputTypeFile
 	// OutputTypeUDP represents a UDP output destination
 	OutputTypeUDP = config.OutputTypeUDP
+	// OutputTypeTCP represents a TCP output destination
+	OutputTypeTCP = config.OutputTypeTCP
+	// OutputTypeSyslog represents a syslog output destination over UDP or TCP
+	OutputTypeSyslog = config.OutputTypeSyslog
+	// OutputTypeHTTP represents an HTTP endpoint receiving batches of logs
+	OutputTypeHTTP = config.OutputTypeHTTP
+	// OutputTypeStdout represents the standard output stream
+	OutputTypeStdout = config.OutputTypeStdout
+	// OutputTypeStderr represents the standard error stream
+	OutputTypeStderr = config.OutputTypeStderr
+)
+
+// DistributionType represents the statistical distribution of a numeric custom type
+type DistributionType = config.DistributionType
+
+const (
+	// DistributionNormal is a normal distribution with a mean and standard deviation
+	DistributionNormal = config.DistributionNormal
+	// DistributionExponential is an exponential distribution with a rate
+	DistributionExponential = config.DistributionExponential
+	// DistributionLognormal is a distribution whose logarithm is normal
+	DistributionLognormal = config.DistributionLognormal
+	// DistributionPareto is a Pareto distribution with a shape and minimum
+	DistributionPareto = config.DistributionPareto
+	// DistributionZipf picks ranks where low ranks are most common
+	DistributionZipf = config.DistributionZipf
+	// DistributionPoisson is a Poisson distribution with a mean
+	DistributionPoisson = config.DistributionPoisson
+	// DistributionUniform picks a number between a min and max
+	DistributionUniform = config.DistributionUniform
+)
+
+// EncoderType represents the format that templates defined as fields are encoded in
+type EncoderType = config.EncoderType
+
+const (
+	// EncoderJSON encodes fields as a JSON object
+	EncoderJSON = config.EncoderJSON
+	// EncoderLogfmt encodes fields as logfmt key=value pairs
+	EncoderLogfmt = config.EncoderLogfmt
+	// EncoderCEF encodes fields as a Common Event Format message
+	EncoderCEF = config.EncoderCEF
+	// EncoderLEEF encodes fields as a Log Event Extended Format message
+	EncoderLEEF = config.EncoderLEEF
+	// EncoderKV encodes fields as key=value pairs with a configurable separator
+	EncoderKV = config.EncoderKV
+	// EncoderCSV encodes the field values as a CSV record
+	EncoderCSV = config.EncoderCSV
 )
@@ -5,6 +5,7 @@ import (
 	"path/filepath"
 	"regexp"
 	"testing"
+	"time"
 
 	"github.com/P1llus/genlog"
 )
@@ -198,3 +199,72 @@ func BenchmarkGenerateLogs(b *testing.B) {
 		}
 	}
 }
+
+// TestNewRenderer tests rendering log lines in memory from a config without outputs
+func TestNewRenderer(t *testing.T) {
+	cfg := &genlog.Config{
+		Templates: []genlog.LogTemplate{
+			{
+				Template: "[{{level}}] Rendered in memory",
+				Weight:   1,
+			},
+		},
+		CustomTypes: map[string][]string{
+			"level": {"INFO", "DEBUG", "ERROR"},
+		},
+		Seed: 12345,
+	}
+
+	renderer, err := genlog.NewRenderer(cfg, 0)
+	if err != nil {
+		t.Fatalf("Failed to create renderer: %v", err)
+	}
+
+	for i := 0; i < 5; i++ {
+		line, err := renderer.GenerateLogLine()
+		if err != nil {
+			t.Fatalf("Failed to generate log line: %v", err)
+		}
+		if matched, _ := regexp.MatchString(`^\[(INFO|DEBUG|ERROR)\] Rendered in memory$`, line); !matched {
+			t.Errorf("Generated log line doesn't match expected pattern. Got: %s", line)
+		}
+	}
+}
+
+// TestNewRendererBackfill tests rendering lines spread over a backfill window
+func TestNewRendererBackfill(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	cfg := &genlog.Config{
+		Templates: []genlog.LogTemplate{
+			{
+				Template: `{{FormattedDate "15:04"}} Backfilled`,
+				Weight:   1,
+			},
+		},
+		Clock: genlog.ClockConfig{
+			Mode:  genlog.ClockModeBackfill,
+			Start: start,
+			End:   start.Add(time.Hour),
+		},
+	}
+
+	if _, err := genlog.NewRenderer(cfg, 0); err == nil {
+		t.Error("Expected error for a backfill clock without a count, got nil")
+	}
+
+	renderer, err := genlog.NewRenderer(cfg, 4)
+	if err != nil {
+		t.Fatalf("Failed to create renderer: %v", err)
+	}
+
+	// The 4 lines are spread evenly over the hour
+	for _, want := range []string{"00:00", "00:15", "00:30", "00:45"} {
+		line, err := renderer.GenerateLogLine()
+		if err != nil {
+			t.Fatalf("Failed to generate log line: %v", err)
+		}
+		if line != want+" Backfilled" {
+			t.Errorf("Generated log line = %q, want %q", line, want+" Backfilled")
+		}
+	}
+}
@@ -4,5 +4,6 @@ go 1.24.1
 
 require (
 	github.com/brianvoe/gofakeit/v7 v7.2.1
+	github.com/klauspost/compress v1.18.0
 	gopkg.in/yaml.v3 v3.0.1
 )
@@ -0,0 +1,146 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// ClockMode represents how the timestamps of generated lines are chosen
+type ClockMode string
+
+const (
+	// ClockModeRandom picks a random time between Start and End for every line,
+	// by default between 2020-01-01 and now
+	ClockModeRandom ClockMode = "random"
+	// ClockModeRealtime uses the current time of every line
+	ClockModeRealtime ClockMode = "realtime"
+	// ClockModeSimulated starts at Start and runs Speed times faster than real time
+	ClockModeSimulated ClockMode = "simulated"
+	// ClockModeBackfill spreads the requested count of lines from Start to End,
+	// as fast as they can be generated
+	ClockModeBackfill ClockMode = "backfill"
+)
+
+// ClockConfig configures the timestamps used by FormattedDate and Now
+type ClockConfig struct {
+	// Mode selects how timestamps are chosen. Defaults to random.
+	Mode ClockMode `yaml:"mode"`
+
+	// Start is the first timestamp for simulated and backfill mode and the
+	// lower bound for random mode. Simulated mode defaults to the current time.
+	Start time.Time `yaml:"start"`
+
+	// End is the end of the backfill window and the upper bound for random mode.
+	// Backfill mode defaults to Start + Window, or the current time.
+	End time.Time `yaml:"end"`
+
+	// Window is the length of the backfill window, e.g. 168h for a week ending
+	// now. It sets Start or End when only one of them is given.
+	Window time.Duration `yaml:"window"`
+
+	// Daily contains 24 weights, one for every hour of the day starting at
+	// midnight in the configured timezone, to spread backfilled lines like
+	// daily activity
+	Daily []float64 `yaml:"daily"`
+
+	// Speed is how many simulated seconds pass per real second in simulated
+	// mode, e.g. 60 makes an hour of logs take a minute. Defaults to 1.
+	Speed float64 `yaml:"speed"`
+
+	// Jitter delays every timestamp in simulated mode by a random amount up to
+	// this duration, so lines don't arrive at perfectly regular intervals.
+	// Timestamps never go backwards.
+	Jitter time.Duration `yaml:"jitter"`
+}
+
+// Validate checks the clock settings for the selected mode
+func (c *ClockConfig) Validate() error {
+	if c.Speed < 0 {
+		return fmt.Errorf("clock speed must not be negative")
+	}
+	if c.Jitter < 0 {
+		return fmt.Errorf("clock jitter must not be negative")
+	}
+	if c.Window < 0 {
+		return fmt.Errorf("clock window must not be negative")
+	}
+	if err := validateDailyWeights(c.Daily); err != nil {
+		return fmt.Errorf("clock %w", err)
+	}
+
+	switch c.Mode {
+	case "", ClockModeRandom:
+		if !c.Start.IsZero() && !c.End.IsZero() && !c.End.After(c.Start) {
+			return fmt.Errorf("clock end must be after start")
+		}
+	case ClockModeRealtime, ClockModeSimulated:
+	case ClockModeBackfill:
+		if c.Start.IsZero() && c.Window == 0 {
+			return fmt.Errorf("backfill clock requires a start or a window")
+		}
+		if !c.Start.IsZero() && !c.End.IsZero() && !c.End.After(c.Start) {
+			return fmt.Errorf("clock end must be after start")
+		}
+	default:
+		return fmt.Errorf("unsupported clock mode: %s", c.Mode)
+	}
+	return nil
+}
+
+// LoadTimezone returns the location for a timezone setting. Besides IANA
+// names such as "Europe/Amsterdam" it accepts "Local", "UTC" and fixed
+// offsets like "+05:45" or "-0930". An empty name is UTC, so timestamps
+// don't depend on the time zone of the host unless "Local" is set.
+func LoadTimezone(name string) (*time.Location, error) {
+	switch name {
+	case "Local":
+		return time.Local, nil
+	case "", "UTC", "Z":
+		return time.UTC, nil
+	}
+
+	if offset, ok := parseOffset(name); ok {
+		return time.FixedZone(name, offset), nil
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("unknown timezone %q: %w", name, err)
+	}
+	return loc, nil
+}
+
+// parseOffset parses a UTC offset such as "+05:45", "-0930" or "+02" into seconds
+func parseOffset(value string) (int, bool) {
+	if len(value) < 3 || (value[0] != '+' && value[0] != '-') {
+		return 0, false
+	}
+
+	digits := value[1:]
+	if len(digits) == 5 && digits[2] == ':' {
+		digits = digits[:2] + digits[3:]
+	}
+	if len(digits) != 2 && len(digits) != 4 {
+		return 0, false
+	}
+	for _, r := range digits {
+		if r < '0' || r > '9' {
+			return 0, false
+		}
+	}
+	hours, _ := strconv.Atoi(digits[:2])
+	minutes := 0
+	if len(digits) == 4 {
+		minutes, _ = strconv.Atoi(digits[2:])
+	}
+	if hours > 23 || minutes > 59 {
+		return 0, false
+	}
+
+	offset := hours*3600 + minutes*60
+	if value[0] == '-' {
+		offset = -offset
+	}
+	return offset, true
+}
@@ -5,7 +5,11 @@ package config
 
 import (
 	"fmt"
+	"net/url"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -14,8 +18,23 @@ import (
 type OutputType string
 
 const (
-	OutputTypeFile OutputType = "file"
-	OutputTypeUDP  OutputType = "udp"
+	OutputTypeFile   OutputType = "file"
+	OutputTypeUDP    OutputType = "udp"
+	OutputTypeTCP    OutputType = "tcp"
+	OutputTypeSyslog OutputType = "syslog"
+	OutputTypeHTTP   OutputType = "http"
+	OutputTypeStdout OutputType = "stdout"
+	OutputTypeStderr OutputType = "stderr"
+)
+
+// TCPFraming represents how messages are delimited on a TCP stream
+type TCPFraming string
+
+const (
+	// TCPFramingNewline terminates every message with a newline (non-transparent framing)
+	TCPFramingNewline TCPFraming = "newline"
+	// TCPFramingOctetCounting prefixes every message with its length as described in RFC 6587
+	TCPFramingOctetCounting TCPFraming = "octet-counting"
 )
 
 // OutputConfig represents a single output configuration
@@ -29,6 +48,21 @@ type OutputConfig struct {
 	// BatchSize specifies the number of logs to write in a single batch
 	BatchSize int `yaml:"batch_size"`
 
+	// Rate limits this output to the given number of events per second,
+	// shared by all of its workers. 0 means unlimited.
+	Rate float64 `yaml:"rate,omitempty"`
+
+	// Burst is the number of events that may be sent at once when the output
+	// has been idle. Defaults to a tenth of a second's worth of events.
+	Burst int `yaml:"burst,omitempty"`
+
+	// LoadProfile varies Rate over time. Requires Rate to be set.
+	LoadProfile *LoadProfile `yaml:"load_profile,omitempty"`
+
+	// Encoder encodes templates defined as fields, such as json or cef.
+	// Defaults to json. Templates defined as a string are written unchanged.
+	Encoder *EncoderConfig `yaml:"encoder,omitempty"`
+
 	// Config contains the type-specific configuration
 	Config map[string]any `yaml:"config"`
 }
@@ -37,6 +71,152 @@ type OutputConfig struct {
 type FileOutputConfig struct {
 	// Filename is the path where logs will be written
 	Filename string `yaml:"filename"`
+
+	// Append adds to an existing file instead of truncating it
+	Append bool `yaml:"append"`
+
+	// Mode is the permission of newly created files, e.g. 0640. Defaults to 0666
+	// before the umask is applied.
+	Mode FileMode `yaml:"mode"`
+
+	// Mkdir creates missing parent directories of Filename
+	Mkdir bool `yaml:"mkdir"`
+
+	// MaxSize rotates the file before a write would make it larger than
+	// this size, e.g. "100MB". 0 disables size based rotation.
+	MaxSize ByteSize `yaml:"max_size"`
+
+	// MaxAge rotates the file once it has been written to for this long,
+	// e.g. "1h". 0 disables time based rotation.
+	MaxAge time.Duration `yaml:"max_age"`
+
+	// MaxBackups is the number of rotated files to keep. Rotated files are
+	// named <filename>.1 (newest) to <filename>.<MaxBackups> (oldest).
+	// 0 keeps all rotated files.
+	MaxBackups int `yaml:"max_backups"`
+
+	// RotateMode selects how the file is rotated. Defaults to rename.
+	RotateMode RotateMode `yaml:"rotate_mode"`
+
+	// CompressBackups gzips rotated files, adding a .gz extension
+	CompressBackups bool `yaml:"compress_backups"`
+
+	// Compression compresses the file while it is written. When empty it is
+	// inferred from a .gz or .zst extension on Filename.
+	Compression FileCompression `yaml:"compression"`
+}
+
+// FileCompression represents the compression applied while writing a file output
+type FileCompression string
+
+const (
+	// FileCompressionNone writes plain text
+	FileCompressionNone FileCompression = "none"
+	// FileCompressionGzip writes a gzip stream
+	FileCompressionGzip FileCompression = "gzip"
+	// FileCompressionZstd writes a zstd stream
+	FileCompressionZstd FileCompression = "zstd"
+)
+
+// Extension returns the filename extension used for files with this compression
+func (c FileCompression) Extension() string {
+	switch c {
+	case FileCompressionGzip:
+		return ".gz"
+	case FileCompressionZstd:
+		return ".zst"
+	default:
+		return ""
+	}
+}
+
+// ResolveFileCompression returns the compression to use for a file output,
+// inferring it from the filename extension when none is configured
+func ResolveFileCompression(compression FileCompression, filename string) FileCompression {
+	if compression != "" {
+		return compression
+	}
+	for _, c := range []FileCompression{FileCompressionGzip, FileCompressionZstd} {
+		if strings.HasSuffix(filename, c.Extension()) {
+			return c
+		}
+	}
+	return FileCompressionNone
+}
+
+// FileMode is a file permission that can be configured as a YAML integer
+// (0640) or as an octal string ("0640")
+type FileMode os.FileMode
+
+// UnmarshalYAML parses a file mode from an integer or an octal string
+func (m *FileMode) UnmarshalYAML(node *yaml.Node) error {
+	if node.Tag == "!!int" {
+		var mode uint32
+		if err := node.Decode(&mode); err != nil {
+			return err
+		}
+		*m = FileMode(mode)
+		return nil
+	}
+
+	mode, err := strconv.ParseUint(node.Value, 8, 32)
+	if err != nil {
+		return fmt.Errorf("invalid file mode %q: must be octal such as 0640", node.Value)
+	}
+	*m = FileMode(mode)
+	return nil
+}
+
+// RotateMode represents how a file output rotates its file
+type RotateMode string
+
+const (
+	// RotateModeRename renames the current file and continues in a newly created file
+	RotateModeRename RotateMode = "rename"
+	// RotateModeCopyTruncate copies the current file and truncates it in place,
+	// keeping the same inode for tailers that don't follow renames
+	RotateModeCopyTruncate RotateMode = "copytruncate"
+)
+
+// ByteSize is a size in bytes that can be configured either as a plain
+// number or with a KB, MB or GB suffix (powers of 1024), e.g. "64MB"
+type ByteSize int64
+
+// UnmarshalYAML parses a byte size from a number or a string with a unit suffix
+func (b *ByteSize) UnmarshalYAML(node *yaml.Node) error {
+	size, err := ParseByteSize(node.Value)
+	if err != nil {
+		return err
+	}
+	*b = size
+	return nil
+}
+
+// ParseByteSize parses a byte size such as "1048576", "512KB" or "1.5GB"
+func ParseByteSize(value string) (ByteSize, error) {
+	value = strings.ToUpper(strings.TrimSpace(value))
+	multiplier := 1.0
+	for _, unit := range []struct {
+		suffix     string
+		multiplier float64
+	}{
+		{"GB", 1 << 30},
+		{"MB", 1 << 20},
+		{"KB", 1 << 10},
+		{"B", 1},
+	} {
+		if strings.HasSuffix(value, unit.suffix) {
+			value = strings.TrimSpace(strings.TrimSuffix(value, unit.suffix))
+			multiplier = unit.multiplier
+			break
+		}
+	}
+
+	size, err := strconv.ParseFloat(value, 64)
+	if err != nil || size < 0 {
+		return 0, fmt.Errorf("invalid byte size: %q", value)
+	}
+	return ByteSize(size * multiplier), nil
 }
 
 // UDPOutputConfig represents configuration specific to UDP outputs
@@ -45,6 +225,173 @@ type UDPOutputConfig struct {
 	Address string `yaml:"address"`
 }
 
+// TCPOutputConfig represents configuration specific to TCP outputs
+type TCPOutputConfig struct {
+	// Address is the TCP destination address (e.g., "localhost:601")
+	Address string `yaml:"address"`
+
+	// Framing selects how messages are delimited on the stream.
+	// Defaults to newline framing.
+	Framing TCPFraming `yaml:"framing"`
+
+	// TLS enables and configures TLS for the connection
+	TLS *TLSConfig `yaml:"tls"`
+
+	// DialTimeout limits how long a single connection attempt may take.
+	// Defaults to 5 seconds.
+	DialTimeout time.Duration `yaml:"dial_timeout"`
+
+	// ReconnectDelay is the initial wait before reconnecting after the
+	// connection drops. It doubles after every failed attempt. Defaults to 500ms.
+	ReconnectDelay time.Duration `yaml:"reconnect_delay"`
+
+	// MaxReconnectDelay caps the backoff between reconnect attempts.
+	// Defaults to 30 seconds.
+	MaxReconnectDelay time.Duration `yaml:"max_reconnect_delay"`
+
+	// MaxRetries is the number of reconnect attempts made for a single batch
+	// before it is dropped. 0 retries until the output is closed.
+	MaxRetries int `yaml:"max_retries"`
+}
+
+// SyslogFormat represents the syslog message format
+type SyslogFormat string
+
+const (
+	// SyslogFormatRFC3164 is the traditional BSD syslog format
+	SyslogFormatRFC3164 SyslogFormat = "rfc3164"
+	// SyslogFormatRFC5424 is the structured IETF syslog format
+	SyslogFormatRFC5424 SyslogFormat = "rfc5424"
+)
+
+// SyslogOutputConfig represents configuration specific to syslog outputs.
+// The embedded TCPOutputConfig settings apply when Network is "tcp".
+type SyslogOutputConfig struct {
+	TCPOutputConfig `yaml:",inline"`
+
+	// Network is the transport used to reach the receiver, "udp" (default) or "tcp"
+	Network string `yaml:"network"`
+
+	// Format selects the syslog message format. Defaults to RFC 5424.
+	Format SyslogFormat `yaml:"format"`
+
+	// Hostname is sent in the HOSTNAME header field. Defaults to the local hostname.
+	Hostname string `yaml:"hostname"`
+
+	// AppName is sent as the APP-NAME (RFC 5424) or TAG (RFC 3164). Defaults to "genlog".
+	AppName string `yaml:"app_name"`
+
+	// ProcID is sent as the PROCID header field. Defaults to the nil value "-".
+	ProcID string `yaml:"procid"`
+
+	// MsgID is sent as the MSGID header field (RFC 5424 only). Defaults to the nil value "-".
+	MsgID string `yaml:"msgid"`
+
+	// Facility is the facility name (e.g. "local0") or code used when
+	// FacilityField is unset or doesn't resolve. Defaults to "user".
+	Facility string `yaml:"facility"`
+
+	// Severity is the severity name (e.g. "warning") or code used when
+	// SeverityField is unset or doesn't resolve. Defaults to "info".
+	Severity string `yaml:"severity"`
+
+	// FacilityField names a custom type or template field whose value
+	// selects the facility of each message
+	FacilityField string `yaml:"facility_field"`
+
+	// SeverityField names a custom type or template field, such as "level",
+	// whose value selects the severity of each message
+	SeverityField string `yaml:"severity_field"`
+
+	// SeverityMap maps field values to severity names for values that are not
+	// already standard names such as "ERROR" or "WARN". Matching ignores case.
+	SeverityMap map[string]string `yaml:"severity_map"`
+
+	// StructuredDataID, when set, adds every field value of a message as a
+	// parameter of an RFC 5424 structured data element with this ID,
+	// e.g. "genlog@32473"
+	StructuredDataID string `yaml:"structured_data_id"`
+}
+
+// HTTPFormat represents how a batch of log lines is encoded in a request body
+type HTTPFormat string
+
+const (
+	// HTTPFormatNDJSON sends one line per log line (newline delimited JSON)
+	HTTPFormatNDJSON HTTPFormat = "ndjson"
+	// HTTPFormatJSON sends the batch as a JSON array
+	HTTPFormatJSON HTTPFormat = "json"
+	// HTTPFormatElasticsearch sends the batch as an Elasticsearch _bulk request
+	HTTPFormatElasticsearch HTTPFormat = "elasticsearch"
+)
+
+// HTTPOutputConfig represents configuration specific to HTTP outputs.
+// Every batch is sent as a single request.
+type HTTPOutputConfig struct {
+	// URL is the endpoint receiving the batches (e.g., "http://localhost:9200/_bulk")
+	URL string `yaml:"url"`
+
+	// Method is the HTTP method used for requests. Defaults to POST.
+	Method string `yaml:"method"`
+
+	// Format selects how the batch is encoded in the request body. Defaults to ndjson.
+	// Lines that are not valid JSON are sent as {"message": "<line>"} by the
+	// json and elasticsearch formats.
+	Format HTTPFormat `yaml:"format"`
+
+	// Index is the target index of the elasticsearch format. When empty the
+	// index must be part of the URL.
+	Index string `yaml:"index"`
+
+	// Headers are added to every request
+	Headers map[string]string `yaml:"headers"`
+
+	// Username and Password enable basic authentication
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+
+	// BearerToken is sent in the Authorization header
+	BearerToken string `yaml:"bearer_token"`
+
+	// Compression compresses request bodies. Only "gzip" is supported.
+	Compression string `yaml:"compression"`
+
+	// Timeout limits how long a single request may take. Defaults to 30 seconds.
+	Timeout time.Duration `yaml:"timeout"`
+
+	// MaxRetries is the number of times a batch is retried after a 429 or 5xx
+	// response or a connection error. Defaults to 3, -1 disables retries.
+	MaxRetries int `yaml:"max_retries"`
+
+	// RetryDelay is the initial wait before retrying a request. It doubles
+	// after every attempt and is capped by MaxRetryDelay. Defaults to 500ms.
+	RetryDelay time.Duration `yaml:"retry_delay"`
+
+	// MaxRetryDelay caps the backoff between retries. Defaults to 30 seconds.
+	MaxRetryDelay time.Duration `yaml:"max_retry_delay"`
+
+	// TLS configures TLS for https endpoints
+	TLS *TLSConfig `yaml:"tls"`
+}
+
+// TLSConfig represents the TLS settings for stream based outputs
+type TLSConfig struct {
+	// CAFile is a PEM encoded CA bundle used to verify the server certificate.
+	// The system roots are used when empty.
+	CAFile string `yaml:"ca_file"`
+
+	// CertFile and KeyFile are a PEM encoded client certificate and key
+	// used for mutual TLS. Both must be set together.
+	CertFile string `yaml:"cert_file"`
+	KeyFile  string `yaml:"key_file"`
+
+	// ServerName overrides the hostname used to verify the server certificate
+	ServerName string `yaml:"server_name"`
+
+	// InsecureSkipVerify disables server certificate verification
+	InsecureSkipVerify bool `yaml:"insecure_skip_verify"`
+}
+
 // Config represents the main configuration structure for the log generator.
 // It defines the templates to use, any custom type definitions, and optional
 // seed value for deterministic generation.
@@ -93,20 +440,60 @@ type Config struct {
 	// Outputs defines the destinations where logs will be sent
 	Outputs []OutputConfig `yaml:"outputs"`
 
+	// Scenarios are sequences of templates mixed into the generated lines
+	Scenarios []Scenario `yaml:"scenarios,omitempty"`
+
 	// CustomTypes is a map of custom type names to their possible values.
 	// These can be referenced in templates and will be selected randomly.
 	// For example, a custom type "username" can be used in templates as {username}.
-	CustomTypes map[string][]string `yaml:"custom_types,omitempty"`
+	// In YAML, custom_types holds these as well as WeightedCustomTypes.
+	CustomTypes map[string][]string `yaml:"-"`
+
+	// WeightedCustomTypes are custom types whose values have weights or that
+	// generate numbers from a distribution. Custom types in YAML that are not
+	// a plain list of values are read into this map. A name must not be used in
+	// both CustomTypes and WeightedCustomTypes.
+	WeightedCustomTypes map[string]CustomType `yaml:"-"`
 
 	// Seed is an optional seed value for deterministic random generation.
 	// Using the same seed will produce the same sequence of logs.
 	// If omitted or set to 0, a random seed will be used.
 	Seed uint64 `yaml:"seed,omitempty"`
+
+	// Clock configures the timestamps returned by FormattedDate and Now
+	Clock ClockConfig `yaml:"clock,omitempty"`
+
+	// Sessions configures the live sessions used by {{session "name"}}
+	Sessions SessionConfig `yaml:"sessions,omitempty"`
+
+	// Timezone is the time zone timestamps are rendered in, such as "UTC",
+	// "America/New_York", "Local" or a fixed offset like "+05:45". Defaults to
+	// UTC. Templates can override it.
+	Timezone string `yaml:"timezone,omitempty"`
+
+	// Duration stops generation after this much time has passed, e.g. "10m".
+	// When a count is given as well, generation ends at whichever comes first.
+	// 0 means no time limit.
+	Duration time.Duration `yaml:"duration,omitempty"`
+
+	// Rate limits the combined events per second of all outputs.
+	// 0 means unlimited. Per output rates apply on top of this limit.
+	Rate float64 `yaml:"rate,omitempty"`
+
+	// Burst is the number of events that may be generated at once when the
+	// global rate limit applies. Defaults to a tenth of a second's worth of events.
+	Burst int `yaml:"burst,omitempty"`
+
+	// LoadProfile varies the global Rate over time. Requires Rate to be set.
+	LoadProfile *LoadProfile `yaml:"load_profile,omitempty"`
 }
 
 // LogTemplate represents a single log template with its selection weight.
 // Templates use the gofakeit syntax for placeholders, such as {name}, {ipv4}, etc.
 type LogTemplate struct {
+	// Name identifies the template in scenario steps
+	Name string `yaml:"name,omitempty"`
+
 	// Template is the log template string with placeholders.
 	// Placeholders are enclosed in curly braces, e.g., {name}, {email}.
 	// Placeholders can be:
@@ -115,11 +502,43 @@ type LogTemplate struct {
 	// - Special functions: {FormattedDate("2006-01-02 15:04:05")}
 	Template string `yaml:"template"`
 
+	// Fields define the template as named values instead, each rendered from
+	// its own template. Outputs encode the fields with their encoder, so
+	// values are escaped for the format of every output.
+	Fields TemplateFields `yaml:"fields,omitempty"`
+
 	// Weight determines the probability of this template being selected.
 	// Higher weights increase the chance of selection.
 	// For example, if template A has weight 10 and template B has weight 5,
 	// template A will be selected roughly twice as often as template B.
+	// Templates with weight 0 are only rendered as part of a scenario.
 	Weight int `yaml:"weight"`
+
+	// Timezone overrides the global timezone for timestamps in this template
+	Timezone string `yaml:"timezone,omitempty"`
+
+	// Vars are rendered once for every line of this template, before the
+	// template itself, and can be used many times in the line with
+	// {{var "name"}}, for example to repeat the same username.
+	Vars map[string]string `yaml:"vars,omitempty"`
+
+	// Session marks the template as the start or end of a session, such as a
+	// login or logout. Without a marker the line uses one of the live sessions.
+	Session SessionMarker `yaml:"session,omitempty"`
+}
+
+// Decode unmarshals the type-specific Config map into a typed configuration
+// struct such as FileOutputConfig or TCPOutputConfig. Durations are given as
+// strings, for example "500ms" or "30s".
+func (o OutputConfig) Decode(v any) error {
+	raw, err := yaml.Marshal(o.Config)
+	if err != nil {
+		return fmt.Errorf("error encoding %s output config: %w", o.Type, err)
+	}
+	if err := yaml.Unmarshal(raw, v); err != nil {
+		return fmt.Errorf("invalid %s output config: %w", o.Type, err)
+	}
+	return nil
 }
 
 // ReadConfig reads and parses the configuration file at the given path.
@@ -151,33 +570,236 @@ func ReadConfig(configFile string) (*Config, error) {
 	return &config, nil
 }
 
-// Validate checks if the configuration is valid
+// WritesToStdout reports whether any output writes generated logs to stdout
+func (c *Config) WritesToStdout() bool {
+	for _, output := range c.Outputs {
+		if output.Type == OutputTypeStdout {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks if the configuration is valid, including its outputs. It
+// also sets the defaults of the outputs.
 func (c *Config) Validate() error {
-	if len(c.Templates) == 0 {
-		return fmt.Errorf("no templates configured")
+	if err := c.ValidateTemplates(); err != nil {
+		return err
 	}
 	if len(c.Outputs) == 0 {
 		return fmt.Errorf("no outputs configured")
 	}
-	for _, output := range c.Outputs {
+	if c.Duration < 0 {
+		return fmt.Errorf("duration must not be negative")
+	}
+	if err := validateRate(c.Rate, c.Burst, c.LoadProfile); err != nil {
+		return err
+	}
+	for i := range c.Outputs {
+		output := &c.Outputs[i]
 		if output.BatchSize == 0 {
 			output.BatchSize = 100
 		}
 		if output.Workers == 0 {
 			output.Workers = 1
 		}
-		switch output.Type {
-		case OutputTypeFile:
-			if _, ok := output.Config["filename"].(string); !ok {
-				return fmt.Errorf("filename is required for file output")
+		if err := validateRate(output.Rate, output.Burst, output.LoadProfile); err != nil {
+			return fmt.Errorf("%s output: %w", output.Type, err)
+		}
+		if output.Encoder != nil {
+			if err := output.Encoder.Validate(); err != nil {
+				return fmt.Errorf("%s output: %w", output.Type, err)
 			}
-		case OutputTypeUDP:
-			if _, ok := output.Config["address"].(string); !ok {
-				return fmt.Errorf("address is required for UDP output")
+		}
+		if err := validateOutput(*output); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ValidateTemplates checks the parts of the configuration needed to render
+// lines: the templates, custom types, sessions, scenarios, clock and time
+// zones. Unlike Validate, it doesn't require or check outputs.
+func (c *Config) ValidateTemplates() error {
+	if len(c.Templates) == 0 {
+		return fmt.Errorf("no templates configured")
+	}
+	if err := c.Clock.Validate(); err != nil {
+		return err
+	}
+	if err := validateCustomTypes(c.CustomTypes, c.WeightedCustomTypes); err != nil {
+		return err
+	}
+	if _, err := LoadTimezone(c.Timezone); err != nil {
+		return err
+	}
+	for i, tpl := range c.Templates {
+		if _, err := LoadTimezone(tpl.Timezone); err != nil {
+			return fmt.Errorf("template %d: %w", i, err)
+		}
+		if err := validateSessionMarker(tpl.Session); err != nil {
+			return fmt.Errorf("template %d: %w", i, err)
+		}
+		if err := validateTemplateFields(tpl); err != nil {
+			return fmt.Errorf("template %d: %w", i, err)
+		}
+		for name, value := range tpl.Vars {
+			if value == "" {
+				return fmt.Errorf("template %d: var %s must not be empty", i, name)
 			}
-		default:
-			return fmt.Errorf("unsupported output type: %s", output.Type)
 		}
 	}
+	if err := c.Sessions.Validate(); err != nil {
+		return err
+	}
+	if err := validateScenarios(c.Scenarios, c.Templates); err != nil {
+		return err
+	}
+	return nil
+}
+
+// validateRate checks a rate limit, its burst size and load profile
+func validateRate(rate float64, burst int, profile *LoadProfile) error {
+	if rate < 0 {
+		return fmt.Errorf("rate must not be negative")
+	}
+	if burst < 0 {
+		return fmt.Errorf("burst must not be negative")
+	}
+	if profile == nil {
+		return nil
+	}
+	if rate == 0 {
+		return fmt.Errorf("load_profile requires a rate")
+	}
+	if err := profile.Validate(); err != nil {
+		return fmt.Errorf("invalid load_profile: %w", err)
+	}
+	return nil
+}
+
+// validateOutput decodes the type-specific configuration of an output and
+// validates it, so the same rules apply as when the output is created
+func validateOutput(output OutputConfig) error {
+	var cfg interface{ Validate() error }
+	switch output.Type {
+	case OutputTypeFile:
+		cfg = &FileOutputConfig{}
+	case OutputTypeUDP:
+		cfg = &UDPOutputConfig{}
+	case OutputTypeTCP:
+		cfg = &TCPOutputConfig{}
+	case OutputTypeSyslog:
+		cfg = &SyslogOutputConfig{}
+	case OutputTypeHTTP:
+		cfg = &HTTPOutputConfig{}
+	case OutputTypeStdout, OutputTypeStderr:
+		// No type-specific configuration
+		return nil
+	default:
+		return fmt.Errorf("unsupported output type: %s", output.Type)
+	}
+	if err := output.Decode(cfg); err != nil {
+		return err
+	}
+	return cfg.Validate()
+}
+
+// Validate checks the file output settings
+func (c *FileOutputConfig) Validate() error {
+	if c.Filename == "" {
+		return fmt.Errorf("filename is required for file output")
+	}
+	if c.Mode > 0o777 {
+		return fmt.Errorf("mode must be a permission between 0000 and 0777")
+	}
+	if c.MaxAge < 0 {
+		return fmt.Errorf("max_age must not be negative")
+	}
+	if c.MaxBackups < 0 {
+		return fmt.Errorf("max_backups must not be negative")
+	}
+	switch c.RotateMode {
+	case "", RotateModeRename, RotateModeCopyTruncate:
+	default:
+		return fmt.Errorf("unsupported rotate_mode: %s", c.RotateMode)
+	}
+	switch compression := ResolveFileCompression(c.Compression, c.Filename); compression {
+	case FileCompressionNone:
+	case FileCompressionGzip, FileCompressionZstd:
+		if c.RotateMode == RotateModeCopyTruncate {
+			return fmt.Errorf("rotate_mode copytruncate can't be used with a compressed file")
+		}
+	default:
+		return fmt.Errorf("compression must be one of none, gzip or zstd, got %s", compression)
+	}
+	return nil
+}
+
+// Validate checks the UDP output settings
+func (c *UDPOutputConfig) Validate() error {
+	if c.Address == "" {
+		return fmt.Errorf("address is required for UDP output")
+	}
+	return nil
+}
+
+// Validate checks the TCP output settings
+func (c *TCPOutputConfig) Validate() error {
+	if c.Address == "" {
+		return fmt.Errorf("address is required for TCP output")
+	}
+	switch c.Framing {
+	case "", TCPFramingNewline, TCPFramingOctetCounting:
+	default:
+		return fmt.Errorf("unsupported TCP framing: %s", c.Framing)
+	}
+	if c.DialTimeout < 0 || c.ReconnectDelay < 0 || c.MaxReconnectDelay < 0 {
+		return fmt.Errorf("dial_timeout, reconnect_delay and max_reconnect_delay must not be negative")
+	}
+	if c.MaxRetries < 0 {
+		return fmt.Errorf("max_retries must not be negative")
+	}
+	return c.TLS.Validate()
+}
+
+// Validate checks the HTTP output settings
+func (c *HTTPOutputConfig) Validate() error {
+	if c.URL == "" {
+		return fmt.Errorf("url is required for HTTP output")
+	}
+	if u, err := url.Parse(c.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("url must be an absolute http or https URL: %s", c.URL)
+	}
+	switch c.Format {
+	case "", HTTPFormatNDJSON, HTTPFormatJSON, HTTPFormatElasticsearch:
+	default:
+		return fmt.Errorf("unsupported HTTP format: %s", c.Format)
+	}
+	if c.Compression != "" && c.Compression != "gzip" {
+		return fmt.Errorf("unsupported HTTP compression: %s", c.Compression)
+	}
+	if c.BearerToken != "" && c.Username != "" {
+		return fmt.Errorf("username and bearer_token cannot be used together")
+	}
+	if c.Timeout < 0 || c.RetryDelay < 0 || c.MaxRetryDelay < 0 {
+		return fmt.Errorf("timeout, retry_delay and max_retry_delay must not be negative")
+	}
+	if c.MaxRetries < -1 {
+		return fmt.Errorf("max_retries must be an integer of -1 or more")
+	}
+	return c.TLS.Validate()
+}
+
+// Validate checks that a client certificate and key are set together. A nil
+// TLSConfig, which disables TLS, is valid.
+func (c *TLSConfig) Validate() error {
+	if c == nil {
+		return nil
+	}
+	if (c.CertFile == "") != (c.KeyFile == "") {
+		return fmt.Errorf("tls cert_file and key_file must be set together")
+	}
 	return nil
 }
@@ -2,7 +2,11 @@ package config
 
 import (
 	"os"
+	"strings"
 	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
 )
 
 func TestReadConfig(t *testing.T) {
@@ -62,6 +66,102 @@ seed: 12345
 	}
 }
 
+func TestWritesToStdout(t *testing.T) {
+	cfg := &Config{
+		Templates: []LogTemplate{{Template: "test template", Weight: 1}},
+		Outputs: []OutputConfig{
+			{Type: OutputTypeStderr},
+		},
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+	if cfg.WritesToStdout() {
+		t.Error("Expected stderr output not to write to stdout")
+	}
+
+	cfg.Outputs = append(cfg.Outputs, OutputConfig{Type: OutputTypeStdout})
+	if !cfg.WritesToStdout() {
+		t.Error("Expected stdout output to write to stdout")
+	}
+}
+
+func TestValidateAppliesOutputDefaults(t *testing.T) {
+	cfg := &Config{
+		Templates: []LogTemplate{{Template: "test template", Weight: 1}},
+		Outputs: []OutputConfig{
+			{
+				Type:   OutputTypeFile,
+				Config: map[string]interface{}{"filename": "test.log"},
+			},
+		},
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+	if cfg.Outputs[0].Workers != 1 {
+		t.Errorf("Expected default workers 1, got %d", cfg.Outputs[0].Workers)
+	}
+	if cfg.Outputs[0].BatchSize != 100 {
+		t.Errorf("Expected default batch size 100, got %d", cfg.Outputs[0].BatchSize)
+	}
+}
+
+func TestValidateTemplatesWithoutOutputs(t *testing.T) {
+	cfg := &Config{
+		Templates: []LogTemplate{{Template: "test template", Weight: 1}},
+	}
+	if err := cfg.ValidateTemplates(); err != nil {
+		t.Errorf("ValidateTemplates failed: %v", err)
+	}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected Validate to require outputs, got nil")
+	}
+
+	cfg.Templates = nil
+	if err := cfg.ValidateTemplates(); err == nil {
+		t.Error("Expected ValidateTemplates to check templates, got nil")
+	}
+}
+
+func TestParseByteSize(t *testing.T) {
+	tests := map[string]ByteSize{
+		"1024":  1024,
+		"512KB": 512 << 10,
+		"10 mb": 10 << 20,
+		"1.5GB": 3 << 29,
+		"100B":  100,
+	}
+	for input, want := range tests {
+		got, err := ParseByteSize(input)
+		if err != nil {
+			t.Errorf("ParseByteSize(%q) failed: %v", input, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("ParseByteSize(%q) = %d, want %d", input, got, want)
+		}
+	}
+	if _, err := ParseByteSize("ten megabytes"); err == nil {
+		t.Error("Expected error for invalid byte size, got nil")
+	}
+}
+
+func TestSyslogCodes(t *testing.T) {
+	if code, ok := SyslogFacility("LOCAL7"); !ok || code != 23 {
+		t.Errorf("SyslogFacility(LOCAL7) = %d, %v, want 23, true", code, ok)
+	}
+	if code, ok := SyslogSeverity("Warning"); !ok || code != 4 {
+		t.Errorf("SyslogSeverity(Warning) = %d, %v, want 4, true", code, ok)
+	}
+	if code, ok := SyslogSeverity("3"); !ok || code != 3 {
+		t.Errorf("SyslogSeverity(3) = %d, %v, want 3, true", code, ok)
+	}
+	if _, ok := SyslogSeverity("8"); ok {
+		t.Error("Expected severity 8 to be rejected")
+	}
+}
+
 func TestReadConfigInvalidFile(t *testing.T) {
 	_, err := ReadConfig("nonexistent.yaml")
 	if err == nil {
@@ -170,15 +270,1222 @@ func TestConfigValidation(t *testing.T) {
 			},
 			wantErr: true,
 		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			// Note: This test assumes we add a Validate method to the Config struct
-			// You might want to add this method to the Config struct
-			err := tt.config.Validate()
-			if (err != nil) != tt.wantErr {
-				t.Errorf("Config.Validate() error = %v, wantErr %v", err, tt.wantErr)
+		{
+			name: "valid TCP output",
+			config: &Config{
+				Templates: []LogTemplate{
+					{
+						Template: "test template",
+						Weight:   1,
+					},
+				},
+				Outputs: []OutputConfig{
+					{
+						Type:    OutputTypeTCP,
+						Workers: 1,
+						Config: map[string]interface{}{
+							"address":         "localhost:601",
+							"framing":         "octet-counting",
+							"reconnect_delay": "1s",
+							"tls": map[string]interface{}{
+								"insecure_skip_verify": true,
+							},
+						},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "TCP output without address",
+			config: &Config{
+				Templates: []LogTemplate{
+					{
+						Template: "test template",
+						Weight:   1,
+					},
+				},
+				Outputs: []OutputConfig{
+					{
+						Type:    OutputTypeTCP,
+						Workers: 1,
+						Config:  map[string]interface{}{},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "TCP output with invalid framing",
+			config: &Config{
+				Templates: []LogTemplate{
+					{
+						Template: "test template",
+						Weight:   1,
+					},
+				},
+				Outputs: []OutputConfig{
+					{
+						Type:    OutputTypeTCP,
+						Workers: 1,
+						Config: map[string]interface{}{
+							"address": "localhost:601",
+							"framing": "length-prefixed",
+						},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "TCP output with client cert but no key",
+			config: &Config{
+				Templates: []LogTemplate{
+					{
+						Template: "test template",
+						Weight:   1,
+					},
+				},
+				Outputs: []OutputConfig{
+					{
+						Type:    OutputTypeTCP,
+						Workers: 1,
+						Config: map[string]interface{}{
+							"address": "localhost:601",
+							"tls": map[string]interface{}{
+								"cert_file": "client.pem",
+							},
+						},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid syslog output",
+			config: &Config{
+				Templates: []LogTemplate{
+					{
+						Template: "test template",
+						Weight:   1,
+					},
+				},
+				Outputs: []OutputConfig{
+					{
+						Type:    OutputTypeSyslog,
+						Workers: 1,
+						Config: map[string]interface{}{
+							"address":        "localhost:514",
+							"network":        "tcp",
+							"format":         "rfc3164",
+							"facility":       "local4",
+							"severity_field": "level",
+							"severity_map": map[string]interface{}{
+								"VERBOSE": "debug",
+							},
+						},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "syslog output with unknown severity",
+			config: &Config{
+				Templates: []LogTemplate{
+					{
+						Template: "test template",
+						Weight:   1,
+					},
+				},
+				Outputs: []OutputConfig{
+					{
+						Type:    OutputTypeSyslog,
+						Workers: 1,
+						Config: map[string]interface{}{
+							"address":  "localhost:514",
+							"severity": "loud",
+						},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "syslog output with a space in the hostname",
+			config: &Config{
+				Templates: []LogTemplate{
+					{
+						Template: "test template",
+						Weight:   1,
+					},
+				},
+				Outputs: []OutputConfig{
+					{
+						Type:    OutputTypeSyslog,
+						Workers: 1,
+						Config: map[string]interface{}{
+							"address":  "localhost:514",
+							"hostname": "web 01",
+						},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "syslog output with a msgid over 32 characters",
+			config: &Config{
+				Templates: []LogTemplate{
+					{
+						Template: "test template",
+						Weight:   1,
+					},
+				},
+				Outputs: []OutputConfig{
+					{
+						Type:    OutputTypeSyslog,
+						Workers: 1,
+						Config: map[string]interface{}{
+							"address": "localhost:514",
+							"msgid":   strings.Repeat("x", 33),
+						},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "syslog output with an rfc5424 app_name",
+			config: &Config{
+				Templates: []LogTemplate{
+					{
+						Template: "test template",
+						Weight:   1,
+					},
+				},
+				Outputs: []OutputConfig{
+					{
+						Type:    OutputTypeSyslog,
+						Workers: 1,
+						Config: map[string]interface{}{
+							"address":  "localhost:514",
+							"app_name": "auth-service",
+						},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "syslog output with an rfc3164 tag that is not alphanumeric",
+			config: &Config{
+				Templates: []LogTemplate{
+					{
+						Template: "test template",
+						Weight:   1,
+					},
+				},
+				Outputs: []OutputConfig{
+					{
+						Type:    OutputTypeSyslog,
+						Workers: 1,
+						Config: map[string]interface{}{
+							"address":  "localhost:514",
+							"format":   "rfc3164",
+							"app_name": "auth-service",
+						},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid HTTP output",
+			config: &Config{
+				Templates: []LogTemplate{
+					{
+						Template: "test template",
+						Weight:   1,
+					},
+				},
+				Outputs: []OutputConfig{
+					{
+						Type:    OutputTypeHTTP,
+						Workers: 1,
+						Config: map[string]interface{}{
+							"url":          "https://localhost:9200/_bulk",
+							"format":       "elasticsearch",
+							"bearer_token": "token",
+							"compression":  "gzip",
+							"max_retries":  5,
+						},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "HTTP output with relative URL",
+			config: &Config{
+				Templates: []LogTemplate{
+					{
+						Template: "test template",
+						Weight:   1,
+					},
+				},
+				Outputs: []OutputConfig{
+					{
+						Type:    OutputTypeHTTP,
+						Workers: 1,
+						Config: map[string]interface{}{
+							"url": "/_bulk",
+						},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "HTTP output with unknown format",
+			config: &Config{
+				Templates: []LogTemplate{
+					{
+						Template: "test template",
+						Weight:   1,
+					},
+				},
+				Outputs: []OutputConfig{
+					{
+						Type:    OutputTypeHTTP,
+						Workers: 1,
+						Config: map[string]interface{}{
+							"url":    "http://localhost:8080",
+							"format": "xml",
+						},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "file output with rotation",
+			config: &Config{
+				Templates: []LogTemplate{
+					{
+						Template: "test template",
+						Weight:   1,
+					},
+				},
+				Outputs: []OutputConfig{
+					{
+						Type:    OutputTypeFile,
+						Workers: 1,
+						Config: map[string]interface{}{
+							"filename":         "test.log",
+							"max_size":         "10MB",
+							"max_age":          "1h",
+							"max_backups":      5,
+							"rotate_mode":      "copytruncate",
+							"compress_backups": true,
+						},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "file output with invalid rotate mode",
+			config: &Config{
+				Templates: []LogTemplate{
+					{
+						Template: "test template",
+						Weight:   1,
+					},
+				},
+				Outputs: []OutputConfig{
+					{
+						Type:    OutputTypeFile,
+						Workers: 1,
+						Config: map[string]interface{}{
+							"filename":    "test.log",
+							"rotate_mode": "move",
+						},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "file output with append, mode and mkdir",
+			config: &Config{
+				Templates: []LogTemplate{
+					{
+						Template: "test template",
+						Weight:   1,
+					},
+				},
+				Outputs: []OutputConfig{
+					{
+						Type:    OutputTypeFile,
+						Workers: 1,
+						Config: map[string]interface{}{
+							"filename": "logs/test.log",
+							"append":   true,
+							"mode":     "0640",
+							"mkdir":    true,
+						},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "compressed file output with copytruncate",
+			config: &Config{
+				Templates: []LogTemplate{
+					{
+						Template: "test template",
+						Weight:   1,
+					},
+				},
+				Outputs: []OutputConfig{
+					{
+						Type:    OutputTypeFile,
+						Workers: 1,
+						Config: map[string]interface{}{
+							"filename":    "test.log.gz",
+							"rotate_mode": "copytruncate",
+						},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "file output with invalid compression",
+			config: &Config{
+				Templates: []LogTemplate{
+					{
+						Template: "test template",
+						Weight:   1,
+					},
+				},
+				Outputs: []OutputConfig{
+					{
+						Type:    OutputTypeFile,
+						Workers: 1,
+						Config: map[string]interface{}{
+							"filename":    "test.log",
+							"compression": "lz4",
+						},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative custom type weight",
+			config: &Config{
+				Templates: []LogTemplate{
+					{
+						Template: "{{level}}",
+						Weight:   1,
+					},
+				},
+				WeightedCustomTypes: map[string]CustomType{
+					"level": {Values: []CustomValue{{Value: "INFO", Weight: -1}}},
+				},
+				Outputs: []OutputConfig{
+					{
+						Type:    OutputTypeStdout,
+						Workers: 1,
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "custom type with and without weights",
+			config: &Config{
+				Templates: []LogTemplate{
+					{
+						Template: "{{level}}",
+						Weight:   1,
+					},
+				},
+				CustomTypes: map[string][]string{
+					"level": {"INFO"},
+				},
+				WeightedCustomTypes: map[string]CustomType{
+					"level": {Values: []CustomValue{{Value: "INFO", Weight: 2}}},
+				},
+				Outputs: []OutputConfig{
+					{
+						Type:    OutputTypeStdout,
+						Workers: 1,
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "unsupported session marker",
+			config: &Config{
+				Templates: []LogTemplate{
+					{
+						Template: "{{session \"user\"}}",
+						Weight:   1,
+						Session:  "begin",
+					},
+				},
+				Outputs: []OutputConfig{
+					{
+						Type:    OutputTypeStdout,
+						Workers: 1,
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative session concurrency",
+			config: &Config{
+				Templates: []LogTemplate{
+					{
+						Template: "{{session \"user\"}}",
+						Weight:   1,
+					},
+				},
+				Outputs: []OutputConfig{
+					{
+						Type:    OutputTypeStdout,
+						Workers: 1,
+					},
+				},
+				Sessions: SessionConfig{Concurrency: -1},
+			},
+			wantErr: true,
+		},
+		{
+			name: "scenario step with unknown template",
+			config: &Config{
+				Templates: []LogTemplate{
+					{
+						Name:     "failed_login",
+						Template: "failed login",
+						Weight:   1,
+					},
+				},
+				Scenarios: []Scenario{
+					{
+						Name:   "brute_force",
+						Weight: 1,
+						Steps: []ScenarioStep{
+							{Template: "failed_login", Repeat: 5},
+							{Template: "successful_login"},
+						},
+					},
+				},
+				Outputs: []OutputConfig{
+					{
+						Type:    OutputTypeStdout,
+						Workers: 1,
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "duplicate template names",
+			config: &Config{
+				Templates: []LogTemplate{
+					{
+						Name:     "login",
+						Template: "login",
+						Weight:   1,
+					},
+					{
+						Name:     "login",
+						Template: "logout",
+						Weight:   1,
+					},
+				},
+				Outputs: []OutputConfig{
+					{
+						Type:    OutputTypeStdout,
+						Workers: 1,
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "template with string and fields",
+			config: &Config{
+				Templates: []LogTemplate{
+					{
+						Template: "test template",
+						Fields:   TemplateFields{{Name: "msg", Template: "test"}},
+						Weight:   1,
+					},
+				},
+				Outputs: []OutputConfig{
+					{
+						Type:    OutputTypeStdout,
+						Workers: 1,
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "duplicate template fields",
+			config: &Config{
+				Templates: []LogTemplate{
+					{
+						Fields: TemplateFields{
+							{Name: "msg", Template: "a"},
+							{Name: "msg", Template: "b"},
+						},
+						Weight: 1,
+					},
+				},
+				Outputs: []OutputConfig{
+					{
+						Type:    OutputTypeStdout,
+						Workers: 1,
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "unsupported encoder",
+			config: &Config{
+				Templates: []LogTemplate{
+					{
+						Fields: TemplateFields{{Name: "msg", Template: "test"}},
+						Weight: 1,
+					},
+				},
+				Outputs: []OutputConfig{
+					{
+						Type:    OutputTypeStdout,
+						Workers: 1,
+						Encoder: &EncoderConfig{Type: "xml"},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative output rate",
+			config: &Config{
+				Templates: []LogTemplate{
+					{
+						Template: "test template",
+						Weight:   1,
+					},
+				},
+				Outputs: []OutputConfig{
+					{
+						Type:    OutputTypeFile,
+						Workers: 2,
+						Rate:    -1,
+						Config: map[string]interface{}{
+							"filename": "test.log",
+						},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "global and output rate",
+			config: &Config{
+				Templates: []LogTemplate{
+					{
+						Template: "test template",
+						Weight:   1,
+					},
+				},
+				Rate:  1000,
+				Burst: 100,
+				Outputs: []OutputConfig{
+					{
+						Type:    OutputTypeFile,
+						Workers: 2,
+						Rate:    500,
+						Config: map[string]interface{}{
+							"filename": "test.log",
+						},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "load profile without rate",
+			config: &Config{
+				Templates: []LogTemplate{
+					{
+						Template: "test template",
+						Weight:   1,
+					},
+				},
+				LoadProfile: &LoadProfile{
+					Sine: &SineProfile{Period: time.Hour},
+				},
+				Outputs: []OutputConfig{
+					{
+						Type:    OutputTypeStdout,
+						Workers: 1,
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "output load profile with invalid daily weights",
+			config: &Config{
+				Templates: []LogTemplate{
+					{
+						Template: "test template",
+						Weight:   1,
+					},
+				},
+				Outputs: []OutputConfig{
+					{
+						Type:    OutputTypeStdout,
+						Workers: 1,
+						Rate:    100,
+						LoadProfile: &LoadProfile{
+							Daily: []float64{1, 2, 3},
+						},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "file output with invalid mode",
+			config: &Config{
+				Templates: []LogTemplate{
+					{
+						Template: "test template",
+						Weight:   1,
+					},
+				},
+				Outputs: []OutputConfig{
+					{
+						Type:    OutputTypeFile,
+						Workers: 1,
+						Config: map[string]interface{}{
+							"filename": "test.log",
+							"mode":     "rw-r-----",
+						},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "file output with a non boolean append",
+			config: &Config{
+				Templates: []LogTemplate{
+					{
+						Template: "test template",
+						Weight:   1,
+					},
+				},
+				Outputs: []OutputConfig{
+					{
+						Type:    OutputTypeFile,
+						Workers: 1,
+						Config: map[string]interface{}{
+							"filename": "test.log",
+							"append":   "maybe",
+						},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "TCP output with a negative reconnect delay",
+			config: &Config{
+				Templates: []LogTemplate{
+					{
+						Template: "test template",
+						Weight:   1,
+					},
+				},
+				Outputs: []OutputConfig{
+					{
+						Type:    OutputTypeTCP,
+						Workers: 1,
+						Config: map[string]interface{}{
+							"address":         "localhost:601",
+							"reconnect_delay": -time.Second,
+						},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "TCP output with durations",
+			config: &Config{
+				Templates: []LogTemplate{
+					{
+						Template: "test template",
+						Weight:   1,
+					},
+				},
+				Outputs: []OutputConfig{
+					{
+						Type:    OutputTypeTCP,
+						Workers: 1,
+						Config: map[string]interface{}{
+							"address":         "localhost:601",
+							"dial_timeout":    time.Second,
+							"reconnect_delay": "250ms",
+						},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "syslog over TCP with invalid framing",
+			config: &Config{
+				Templates: []LogTemplate{
+					{
+						Template: "test template",
+						Weight:   1,
+					},
+				},
+				Outputs: []OutputConfig{
+					{
+						Type:    OutputTypeSyslog,
+						Workers: 1,
+						Config: map[string]interface{}{
+							"address": "localhost:601",
+							"network": "tcp",
+							"framing": "length",
+						},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "HTTP output with max retries below -1",
+			config: &Config{
+				Templates: []LogTemplate{
+					{
+						Template: "test template",
+						Weight:   1,
+					},
+				},
+				Outputs: []OutputConfig{
+					{
+						Type:    OutputTypeHTTP,
+						Workers: 1,
+						Config: map[string]interface{}{
+							"url":         "http://localhost:9200/_bulk",
+							"max_retries": -2,
+						},
+					},
+				},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Note: This test assumes we add a Validate method to the Config struct
+			// You might want to add this method to the Config struct
+			err := tt.config.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Config.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFileModeUnmarshal(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    FileMode
+		wantErr bool
+	}{
+		{input: "mode: 0640", want: 0o640},
+		{input: `mode: "0600"`, want: 0o600},
+		{input: `mode: "644"`, want: 0o644},
+		{input: "mode: 416", want: 0o640},
+		{input: `mode: "rw-r-----"`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			var cfg FileOutputConfig
+			err := yaml.Unmarshal([]byte(tt.input), &cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Unmarshal() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && cfg.Mode != tt.want {
+				t.Errorf("Mode = %o, want %o", cfg.Mode, tt.want)
+			}
+		})
+	}
+}
+
+func TestCustomValueUnmarshal(t *testing.T) {
+	content := `
+level:
+  - value: INFO
+    weight: 90
+  - value: ERROR
+  - value: DEBUG
+    weight: 0
+  - WARN
+`
+	var customTypes map[string][]CustomValue
+	if err := yaml.Unmarshal([]byte(content), &customTypes); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+
+	want := []CustomValue{
+		{Value: "INFO", Weight: 90},
+		{Value: "ERROR", Weight: 1},
+		{Value: "DEBUG", Weight: 0},
+		{Value: "WARN", Weight: 1},
+	}
+	got := customTypes["level"]
+	if len(got) != len(want) {
+		t.Fatalf("Got %d values, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Value %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestReadConfigFields(t *testing.T) {
+	content := `
+templates:
+  - weight: 1
+    fields:
+      timestamp: "{{FormattedDate \"2006-01-02T15:04:05Z07:00\"}}"
+      level: "{{level}}"
+      msg: "{{HackerPhrase}}"
+outputs:
+  - type: stdout
+    encoder: logfmt
+  - type: udp
+    encoder:
+      type: cef
+      vendor: Acme
+      product: Firewall
+`
+	var cfg Config
+	if err := yaml.Unmarshal([]byte(content), &cfg); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+
+	// Fields keep the order of the configuration
+	want := []string{"timestamp", "level", "msg"}
+	fields := cfg.Templates[0].Fields
+	if len(fields) != len(want) {
+		t.Fatalf("Got %d fields, want %d", len(fields), len(want))
+	}
+	for i, name := range want {
+		if fields[i].Name != name {
+			t.Errorf("Field %d = %s, want %s", i, fields[i].Name, name)
+		}
+	}
+	if fields[1].Template != "{{level}}" {
+		t.Errorf("Unexpected level template %q", fields[1].Template)
+	}
+
+	if enc := cfg.Outputs[0].Encoder; enc == nil || enc.Type != EncoderLogfmt {
+		t.Errorf("Unexpected stdout encoder: %+v", enc)
+	}
+	if enc := cfg.Outputs[1].Encoder; enc == nil || enc.Type != EncoderCEF || enc.Vendor != "Acme" || enc.Product != "Firewall" {
+		t.Errorf("Unexpected udp encoder: %+v", enc)
+	}
+
+	if err := yaml.Unmarshal([]byte("fields: [msg]"), &LogTemplate{}); err == nil {
+		t.Error("Expected error for fields that are not a mapping, got nil")
+	}
+}
+
+func TestReadConfigDistribution(t *testing.T) {
+	content := `
+level:
+  - value: INFO
+    weight: 9
+  - ERROR
+latency_ms:
+  distribution: lognormal
+  mu: 4
+  sigma: 0.6
+  min: 1
+  max: 30000
+  format: "%.0f"
+`
+	var customTypes map[string]CustomType
+	if err := yaml.Unmarshal([]byte(content), &customTypes); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+
+	if level := customTypes["level"]; len(level.Values) != 2 || level.Distribution != nil {
+		t.Errorf("Unexpected level custom type: %+v", level)
+	}
+	latency := customTypes["latency_ms"].Distribution
+	if latency == nil {
+		t.Fatal("Expected latency_ms to be a distribution")
+	}
+	if latency.Type != DistributionLognormal || latency.Mu != 4 || latency.Sigma != 0.6 || latency.Format != "%.0f" {
+		t.Errorf("Unexpected distribution: %+v", *latency)
+	}
+	if latency.Min == nil || *latency.Min != 1 || latency.Max == nil || *latency.Max != 30000 {
+		t.Errorf("Unexpected bounds: min %v, max %v", latency.Min, latency.Max)
+	}
+	if err := latency.Validate(); err != nil {
+		t.Errorf("Validate failed: %v", err)
+	}
+
+	if err := yaml.Unmarshal([]byte("level: INFO"), &customTypes); err == nil {
+		t.Error("Expected error for a custom type that is a single string, got nil")
+	}
+}
+
+func TestReadConfigCustomTypes(t *testing.T) {
+	content := `
+templates:
+  - template: "{{service}} {{level}} {{latency_ms}}"
+    weight: 1
+custom_types:
+  service:
+    - API
+    - value: AUTH
+  level:
+    - value: INFO
+      weight: 9
+    - ERROR
+  latency_ms:
+    distribution: exponential
+    rate: 0.1
+`
+	var cfg Config
+	if err := yaml.Unmarshal([]byte(content), &cfg); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+
+	// Plain lists keep being read into CustomTypes
+	if service := cfg.CustomTypes["service"]; len(service) != 2 || service[0] != "API" || service[1] != "AUTH" {
+		t.Errorf("Unexpected service custom type: %v", service)
+	}
+	if len(cfg.CustomTypes) != 1 {
+		t.Errorf("Expected only service in CustomTypes, got %v", cfg.CustomTypes)
+	}
+	if level := cfg.WeightedCustomTypes["level"]; len(level.Values) != 2 || level.Values[0].Weight != 9 {
+		t.Errorf("Unexpected level custom type: %+v", level)
+	}
+	if latency := cfg.WeightedCustomTypes["latency_ms"]; latency.Distribution == nil || latency.Distribution.Rate != 0.1 {
+		t.Errorf("Unexpected latency_ms custom type: %+v", latency)
+	}
+	if cfg.Templates[0].Weight != 1 {
+		t.Errorf("Expected the rest of the config to be read, got %+v", cfg.Templates)
+	}
+	if all := cfg.AllCustomTypes(); len(all) != 3 || all["service"].Values[1].Weight != 1 {
+		t.Errorf("Unexpected custom types: %+v", all)
+	}
+}
+
+func TestDistributionValidate(t *testing.T) {
+	one, ten := 1.0, 10.0
+	tests := []struct {
+		name         string
+		distribution Distribution
+		wantErr      bool
+	}{
+		{name: "normal", distribution: Distribution{Type: DistributionNormal, Mean: 100, StdDev: 15}},
+		{name: "normal without stddev", distribution: Distribution{Type: DistributionNormal, Mean: 100}, wantErr: true},
+		{name: "exponential", distribution: Distribution{Type: DistributionExponential, Rate: 0.5}},
+		{name: "exponential without rate", distribution: Distribution{Type: DistributionExponential}, wantErr: true},
+		{name: "pareto without scale", distribution: Distribution{Type: DistributionPareto, Alpha: 1.16}, wantErr: true},
+		{name: "zipf", distribution: Distribution{Type: DistributionZipf, S: 1.1, N: 50}},
+		{name: "zipf without ranks", distribution: Distribution{Type: DistributionZipf, S: 1.1}, wantErr: true},
+		{name: "poisson without lambda", distribution: Distribution{Type: DistributionPoisson}, wantErr: true},
+		{name: "uniform", distribution: Distribution{Type: DistributionUniform, Min: &one, Max: &ten}},
+		{name: "uniform without max", distribution: Distribution{Type: DistributionUniform, Min: &one}, wantErr: true},
+		{name: "max below min", distribution: Distribution{Type: DistributionPoisson, Lambda: 3, Min: &ten, Max: &one}, wantErr: true},
+		{name: "invalid format", distribution: Distribution{Type: DistributionPoisson, Lambda: 3, Format: "%d"}, wantErr: true},
+		{name: "missing type", distribution: Distribution{Mean: 1}, wantErr: true},
+		{name: "unsupported type", distribution: Distribution{Type: "gamma"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.distribution.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestReadConfigLoadProfile(t *testing.T) {
+	content := `
+templates:
+  - template: "test"
+    weight: 1
+outputs:
+  - type: stdout
+rate: 500
+load_profile:
+  ramp:
+    from: 0.1
+    to: 1
+    duration: 10m
+  sine:
+    period: 1h
+    amplitude: 0.3
+  bursts:
+    - multiplier: 10
+      duration: 30s
+      every: 10m
+`
+	tmpfile, err := os.CreateTemp("", "config-*.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tmpfile.Name())
+	if _, err := tmpfile.Write([]byte(content)); err != nil {
+		t.Fatal(err)
+	}
+	tmpfile.Close()
+
+	cfg, err := ReadConfig(tmpfile.Name())
+	if err != nil {
+		t.Fatalf("ReadConfig failed: %v", err)
+	}
+
+	profile := cfg.LoadProfile
+	if profile == nil || profile.Ramp == nil || profile.Sine == nil || len(profile.Bursts) != 1 {
+		t.Fatalf("Load profile not parsed: %+v", profile)
+	}
+	if profile.Ramp.Duration != 10*time.Minute || profile.Sine.Amplitude != 0.3 {
+		t.Errorf("Unexpected profile values: ramp %+v, sine %+v", *profile.Ramp, *profile.Sine)
+	}
+	if burst := profile.Bursts[0]; burst.Multiplier != 10 || burst.Duration != 30*time.Second || burst.Every != 10*time.Minute {
+		t.Errorf("Unexpected burst: %+v", burst)
+	}
+}
+
+func TestReadConfigClock(t *testing.T) {
+	content := `
+templates:
+  - template: "test"
+    weight: 1
+outputs:
+  - type: stdout
+clock:
+  mode: backfill
+  start: 2024-01-01T00:00:00Z
+  end: "2024-01-02T00:00:00Z"
+`
+	tmpfile, err := os.CreateTemp("", "config-*.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tmpfile.Name())
+	if _, err := tmpfile.Write([]byte(content)); err != nil {
+		t.Fatal(err)
+	}
+	tmpfile.Close()
+
+	cfg, err := ReadConfig(tmpfile.Name())
+	if err != nil {
+		t.Fatalf("ReadConfig failed: %v", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+
+	want := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	if cfg.Clock.Mode != ClockModeBackfill || !cfg.Clock.Start.Equal(want) || !cfg.Clock.End.Equal(want.Add(24*time.Hour)) {
+		t.Errorf("Unexpected clock config: %+v", cfg.Clock)
+	}
+}
+
+func TestClockValidate(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		clock   ClockConfig
+		wantErr bool
+	}{
+		{name: "default", clock: ClockConfig{}},
+		{name: "simulated", clock: ClockConfig{Mode: ClockModeSimulated, Speed: 10, Jitter: time.Second}},
+		{name: "backfill", clock: ClockConfig{Mode: ClockModeBackfill, Start: start, End: start.Add(time.Hour)}},
+		{name: "backfill ending now", clock: ClockConfig{Mode: ClockModeBackfill, Window: 168 * time.Hour}},
+		{name: "backfill without start or window", clock: ClockConfig{Mode: ClockModeBackfill, End: start}, wantErr: true},
+		{name: "backfill with invalid daily weights", clock: ClockConfig{Mode: ClockModeBackfill, Window: time.Hour, Daily: []float64{1}}, wantErr: true},
+		{name: "end before start", clock: ClockConfig{Start: start, End: start.Add(-time.Hour)}, wantErr: true},
+		{name: "negative speed", clock: ClockConfig{Mode: ClockModeSimulated, Speed: -1}, wantErr: true},
+		{name: "unknown mode", clock: ClockConfig{Mode: "future"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.clock.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadTimezone(t *testing.T) {
+	tests := []struct {
+		name       string
+		wantOffset int
+		wantErr    bool
+	}{
+		{name: "UTC", wantOffset: 0},
+		{name: "+05:45", wantOffset: 5*3600 + 45*60},
+		{name: "-0930", wantOffset: -(9*3600 + 30*60)},
+		{name: "+14", wantOffset: 14 * 3600},
+		{name: "Asia/Kolkata", wantOffset: 5*3600 + 30*60},
+		{name: "+25:00", wantErr: true},
+		{name: "Mars/Olympus_Mons", wantErr: true},
+	}
+
+	if loc, err := LoadTimezone(""); err != nil || loc != time.UTC {
+		t.Errorf("LoadTimezone(\"\") = %v, %v, want UTC", loc, err)
+	}
+	if loc, err := LoadTimezone("Local"); err != nil || loc != time.Local {
+		t.Errorf("LoadTimezone(\"Local\") = %v, %v, want the local time zone", loc, err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loc, err := LoadTimezone(tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadTimezone() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			_, offset := time.Date(2024, 1, 1, 0, 0, 0, 0, loc).Zone()
+			if offset != tt.wantOffset {
+				t.Errorf("Offset = %d, want %d", offset, tt.wantOffset)
 			}
 		})
 	}
@@ -0,0 +1,174 @@
+package config
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// CustomType is a custom type with weighted values or a numeric distribution,
+// as used by Config.WeightedCustomTypes. In YAML, all custom types are given
+// under custom_types:
+//
+//	custom_types:
+//	  level:
+//	    - value: INFO
+//	      weight: 90
+//	    - value: ERROR
+//	      weight: 1
+//	  service:
+//	    - API
+//	    - AUTH
+//	  latency_ms:
+//	    distribution: lognormal
+//	    mu: 4
+//	    sigma: 0.6
+//	    min: 1
+//	    max: 30000
+//	    format: "%.0f"
+type CustomType struct {
+	// Values are the possible values of the custom type
+	Values []CustomValue
+
+	// Distribution generates numbers instead of selecting one of the Values
+	Distribution *Distribution
+}
+
+// CustomValue is one of the possible values of a custom type with its
+// selection weight. In YAML a value is either a plain string, with weight 1,
+// or a mapping with a value and a weight.
+type CustomValue struct {
+	// Value is the string the custom type renders
+	Value string `yaml:"value"`
+
+	// Weight determines the probability of this value being selected, the same
+	// way template weights do. Defaults to 1 when omitted in YAML.
+	Weight int `yaml:"weight"`
+}
+
+// UnmarshalYAML accepts a list of values or a distribution
+func (c *CustomType) UnmarshalYAML(node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.SequenceNode:
+		return node.Decode(&c.Values)
+	case yaml.MappingNode:
+		c.Distribution = &Distribution{}
+		return node.Decode(c.Distribution)
+	default:
+		return fmt.Errorf("line %d: custom type must be a list of values or a distribution", node.Line)
+	}
+}
+
+// UnmarshalYAML accepts a plain string as well as a value with a weight
+func (v *CustomValue) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		v.Value = node.Value
+		v.Weight = 1
+		return nil
+	}
+
+	var raw struct {
+		Value  string `yaml:"value"`
+		Weight *int   `yaml:"weight"`
+	}
+	if err := node.Decode(&raw); err != nil {
+		return err
+	}
+	v.Value = raw.Value
+	v.Weight = 1
+	if raw.Weight != nil {
+		v.Weight = *raw.Weight
+	}
+	return nil
+}
+
+// Values returns a custom type with the given values and equal weights, the
+// way the plain lists of Config.CustomTypes are selected
+func Values(values ...string) CustomType {
+	result := make([]CustomValue, len(values))
+	for i, value := range values {
+		result[i] = CustomValue{Value: value, Weight: 1}
+	}
+	return CustomType{Values: result}
+}
+
+// plain returns the values of a custom type that is a list of values with
+// equal weights, as read from a plain YAML list
+func (c CustomType) plain() ([]string, bool) {
+	if c.Distribution != nil {
+		return nil, false
+	}
+	values := make([]string, len(c.Values))
+	for i, value := range c.Values {
+		if value.Weight != 1 {
+			return nil, false
+		}
+		values[i] = value.Value
+	}
+	return values, true
+}
+
+// AllCustomTypes returns every custom type of the configuration, with the
+// plain lists of CustomTypes given equal weights
+func (c *Config) AllCustomTypes() map[string]CustomType {
+	customTypes := make(map[string]CustomType, len(c.CustomTypes)+len(c.WeightedCustomTypes))
+	for name, values := range c.CustomTypes {
+		customTypes[name] = Values(values...)
+	}
+	for name, customType := range c.WeightedCustomTypes {
+		customTypes[name] = customType
+	}
+	return customTypes
+}
+
+// UnmarshalYAML decodes a configuration. Custom types that are a plain list
+// of values are read into CustomTypes, all others into WeightedCustomTypes.
+func (c *Config) UnmarshalYAML(node *yaml.Node) error {
+	type plainConfig Config
+	var raw struct {
+		plainConfig `yaml:",inline"`
+		CustomTypes map[string]CustomType `yaml:"custom_types"`
+	}
+	if err := node.Decode(&raw); err != nil {
+		return err
+	}
+
+	*c = Config(raw.plainConfig)
+	for name, customType := range raw.CustomTypes {
+		if values, ok := customType.plain(); ok {
+			if c.CustomTypes == nil {
+				c.CustomTypes = make(map[string][]string)
+			}
+			c.CustomTypes[name] = values
+			continue
+		}
+		if c.WeightedCustomTypes == nil {
+			c.WeightedCustomTypes = make(map[string]CustomType)
+		}
+		c.WeightedCustomTypes[name] = customType
+	}
+	return nil
+}
+
+// validateCustomTypes checks the weights and distributions of every custom type
+func validateCustomTypes(plain map[string][]string, customTypes map[string]CustomType) error {
+	for name := range customTypes {
+		if _, ok := plain[name]; ok {
+			return fmt.Errorf("custom type %s is defined in both CustomTypes and WeightedCustomTypes", name)
+		}
+	}
+	for name, customType := range customTypes {
+		if customType.Distribution != nil {
+			if err := customType.Distribution.Validate(); err != nil {
+				return fmt.Errorf("custom type %s: %w", name, err)
+			}
+			continue
+		}
+		for _, value := range customType.Values {
+			if value.Weight < 0 {
+				return fmt.Errorf("custom type %s: weight of %q must not be negative", name, value.Value)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DistributionType represents the statistical distribution of a numeric custom type
+type DistributionType string
+
+const (
+	// DistributionNormal is a normal distribution with Mean and StdDev
+	DistributionNormal DistributionType = "normal"
+	// DistributionExponential is an exponential distribution with Rate
+	DistributionExponential DistributionType = "exponential"
+	// DistributionLognormal is a distribution whose logarithm is normal with Mu and Sigma
+	DistributionLognormal DistributionType = "lognormal"
+	// DistributionPareto is a Pareto distribution with shape Alpha and minimum Scale
+	DistributionPareto DistributionType = "pareto"
+	// DistributionZipf picks ranks from 1 to N with exponent S, so low ranks are most common
+	DistributionZipf DistributionType = "zipf"
+	// DistributionPoisson is a Poisson distribution with Lambda events on average
+	DistributionPoisson DistributionType = "poisson"
+	// DistributionUniform picks a number between Min and Max
+	DistributionUniform DistributionType = "uniform"
+)
+
+// maxZipfRanks limits the number of ranks of a zipf distribution, as every
+// rank is kept in memory to pick from
+const maxZipfRanks = 1_000_000
+
+// Distribution configures a custom type that renders random numbers with a
+// realistic statistical shape, such as response times or bytes sent.
+// Only the parameters of the selected distribution are used.
+type Distribution struct {
+	// Type selects the distribution
+	Type DistributionType `yaml:"distribution"`
+
+	// Mean and StdDev are the parameters of the normal distribution
+	Mean   float64 `yaml:"mean,omitempty"`
+	StdDev float64 `yaml:"stddev,omitempty"`
+
+	// Rate is the number of events per unit of the exponential distribution,
+	// making 1/Rate the mean
+	Rate float64 `yaml:"rate,omitempty"`
+
+	// Mu and Sigma are the mean and standard deviation of the logarithm of
+	// the lognormal distribution
+	Mu    float64 `yaml:"mu,omitempty"`
+	Sigma float64 `yaml:"sigma,omitempty"`
+
+	// Alpha is the shape and Scale the smallest value of the Pareto distribution
+	Alpha float64 `yaml:"alpha,omitempty"`
+	Scale float64 `yaml:"scale,omitempty"`
+
+	// S is the exponent and N the number of ranks of the zipf distribution
+	S float64 `yaml:"s,omitempty"`
+	N int     `yaml:"n,omitempty"`
+
+	// Lambda is the mean of the Poisson distribution
+	Lambda float64 `yaml:"lambda,omitempty"`
+
+	// Min and Max are the bounds of the uniform distribution. For the other
+	// distributions they clamp the generated numbers.
+	Min *float64 `yaml:"min,omitempty"`
+	Max *float64 `yaml:"max,omitempty"`
+
+	// Format is the fmt verb used to render the numbers, such as "%.0f" or
+	// "%.3f". Defaults to "%.0f" for zipf and poisson and "%.2f" otherwise.
+	Format string `yaml:"format,omitempty"`
+}
+
+// Validate checks the parameters of the selected distribution
+func (d *Distribution) Validate() error {
+	switch d.Type {
+	case DistributionNormal:
+		if d.StdDev <= 0 {
+			return fmt.Errorf("normal distribution requires a positive stddev")
+		}
+	case DistributionExponential:
+		if d.Rate <= 0 {
+			return fmt.Errorf("exponential distribution requires a positive rate")
+		}
+	case DistributionLognormal:
+		if d.Sigma <= 0 {
+			return fmt.Errorf("lognormal distribution requires a positive sigma")
+		}
+	case DistributionPareto:
+		if d.Alpha <= 0 || d.Scale <= 0 {
+			return fmt.Errorf("pareto distribution requires a positive alpha and scale")
+		}
+	case DistributionZipf:
+		if d.S <= 0 {
+			return fmt.Errorf("zipf distribution requires a positive s")
+		}
+		if d.N < 1 || d.N > maxZipfRanks {
+			return fmt.Errorf("zipf distribution requires n between 1 and %d", maxZipfRanks)
+		}
+	case DistributionPoisson:
+		if d.Lambda <= 0 {
+			return fmt.Errorf("poisson distribution requires a positive lambda")
+		}
+	case DistributionUniform:
+		if d.Min == nil || d.Max == nil {
+			return fmt.Errorf("uniform distribution requires a min and max")
+		}
+	case "":
+		return fmt.Errorf("distribution is required")
+	default:
+		return fmt.Errorf("unsupported distribution: %s", d.Type)
+	}
+
+	if d.Min != nil && d.Max != nil && *d.Max < *d.Min {
+		return fmt.Errorf("distribution max must not be less than min")
+	}
+	if d.Format != "" && strings.Contains(fmt.Sprintf(d.Format, 1.0), "%!") {
+		return fmt.Errorf("invalid distribution format %q", d.Format)
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package config
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// EncoderType represents the format that field templates are encoded in
+type EncoderType string
+
+const (
+	// EncoderJSON encodes fields as a JSON object, the default for field templates
+	EncoderJSON EncoderType = "json"
+	// EncoderLogfmt encodes fields as logfmt key=value pairs
+	EncoderLogfmt EncoderType = "logfmt"
+	// EncoderCEF encodes fields as an ArcSight Common Event Format message
+	EncoderCEF EncoderType = "cef"
+	// EncoderLEEF encodes fields as an IBM QRadar Log Event Extended Format message
+	EncoderLEEF EncoderType = "leef"
+	// EncoderKV encodes fields as key=value pairs with a configurable separator
+	EncoderKV EncoderType = "kv"
+	// EncoderCSV encodes the field values as a CSV record
+	EncoderCSV EncoderType = "csv"
+)
+
+// EncoderConfig configures how an output encodes templates defined as fields.
+// In YAML it is either the encoder type, e.g. encoder: logfmt, or a mapping
+// with the type and its options.
+type EncoderConfig struct {
+	// Type selects the encoder
+	Type EncoderType `yaml:"type"`
+
+	// Vendor, Product and Version fill the CEF and LEEF headers.
+	// Default to genlog, genlog and 1.0.
+	Vendor  string `yaml:"vendor,omitempty"`
+	Product string `yaml:"product,omitempty"`
+	Version string `yaml:"version,omitempty"`
+
+	// EventIDField names the field holding the CEF signature ID or LEEF event
+	// ID. Defaults to event_id, and the ID to 0 when the field is missing.
+	EventIDField string `yaml:"event_id_field,omitempty"`
+
+	// NameField names the field holding the CEF event name. Defaults to name,
+	// and the event name to event when the field is missing.
+	NameField string `yaml:"name_field,omitempty"`
+
+	// SeverityField names the field holding the CEF severity. Defaults to
+	// severity, and the severity to 5 when the field is missing.
+	SeverityField string `yaml:"severity_field,omitempty"`
+
+	// Separator is put between the key=value pairs of the kv encoder, and
+	// between the values of the csv encoder. Defaults to a space for kv and a
+	// comma for csv.
+	Separator string `yaml:"separator,omitempty"`
+}
+
+// UnmarshalYAML accepts an encoder type as well as a mapping with options
+func (e *EncoderConfig) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		e.Type = EncoderType(node.Value)
+		return nil
+	}
+	type plain EncoderConfig
+	return node.Decode((*plain)(e))
+}
+
+// Validate checks the encoder type and options
+func (e *EncoderConfig) Validate() error {
+	switch e.Type {
+	case EncoderJSON, EncoderLogfmt, EncoderCEF, EncoderLEEF, EncoderKV:
+	case EncoderCSV:
+		if e.Separator != "" && utf8.RuneCountInString(e.Separator) != 1 {
+			return fmt.Errorf("csv separator must be a single character")
+		}
+	default:
+		return fmt.Errorf("unsupported encoder: %s", e.Type)
+	}
+	return nil
+}
+
+// TemplateField is a named value of a template defined as fields
+type TemplateField struct {
+	// Name is the key of the field in the encoded line
+	Name string
+
+	// Template renders the value of the field
+	Template string
+}
+
+// TemplateFields are the fields of a template, in the order they are encoded
+type TemplateFields []TemplateField
+
+// UnmarshalYAML reads the fields from a mapping, keeping their order
+func (f *TemplateFields) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		return fmt.Errorf("line %d: fields must be a mapping of names to templates", node.Line)
+	}
+	*f = make(TemplateFields, 0, len(node.Content)/2)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		var field TemplateField
+		if err := node.Content[i].Decode(&field.Name); err != nil {
+			return err
+		}
+		if err := node.Content[i+1].Decode(&field.Template); err != nil {
+			return err
+		}
+		*f = append(*f, field)
+	}
+	return nil
+}
+
+// validateTemplateFields checks that a template is either a string or fields,
+// and that field names are unique
+func validateTemplateFields(tpl LogTemplate) error {
+	if tpl.Template != "" && len(tpl.Fields) > 0 {
+		return fmt.Errorf("template and fields can't both be set")
+	}
+	seen := make(map[string]bool, len(tpl.Fields))
+	for _, field := range tpl.Fields {
+		if field.Name == "" {
+			return fmt.Errorf("field names must not be empty")
+		}
+		if seen[field.Name] {
+			return fmt.Errorf("duplicate field %s", field.Name)
+		}
+		seen[field.Name] = true
+	}
+	return nil
+}
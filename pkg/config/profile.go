@@ -0,0 +1,117 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// LoadProfile describes how a rate changes over time. Every configured shape
+// is a multiplier of the rate, and the shapes are combined by multiplying them,
+// e.g. a daily curve with bursts on top.
+type LoadProfile struct {
+	// Ramp changes the rate linearly after the start of generation
+	Ramp *RampProfile `yaml:"ramp,omitempty"`
+
+	// Sine varies the rate around its configured value in waves
+	Sine *SineProfile `yaml:"sine,omitempty"`
+
+	// Daily contains 24 weights, one for every hour of the day starting at
+	// midnight local time. Weights are relative to their average, so the
+	// rate is reached on average over a day, and are interpolated between hours.
+	Daily []float64 `yaml:"daily,omitempty"`
+
+	// Bursts multiply the rate for a short time at a regular interval
+	Bursts []BurstProfile `yaml:"bursts,omitempty"`
+}
+
+// RampProfile changes the rate multiplier linearly from From to To over
+// Duration, and keeps it at To afterwards
+type RampProfile struct {
+	From     float64       `yaml:"from"`
+	To       float64       `yaml:"to"`
+	Duration time.Duration `yaml:"duration"`
+}
+
+// SineProfile multiplies the rate by 1 + Amplitude*sin(2πt/Period)
+type SineProfile struct {
+	// Period is the length of a full wave
+	Period time.Duration `yaml:"period"`
+
+	// Amplitude is the relative deviation from the rate between 0 and 1.
+	// Defaults to 0.5, varying the rate between half and one and a half times its value.
+	Amplitude float64 `yaml:"amplitude"`
+}
+
+// BurstProfile multiplies the rate by Multiplier for Duration, every Every,
+// starting Offset after the start of generation
+type BurstProfile struct {
+	Multiplier float64       `yaml:"multiplier"`
+	Duration   time.Duration `yaml:"duration"`
+	Every      time.Duration `yaml:"every"`
+	Offset     time.Duration `yaml:"offset"`
+}
+
+// Validate checks that every shape of the profile is usable
+func (p *LoadProfile) Validate() error {
+	if r := p.Ramp; r != nil {
+		if r.Duration <= 0 {
+			return fmt.Errorf("ramp duration must be positive")
+		}
+		if r.From < 0 || r.To < 0 {
+			return fmt.Errorf("ramp from and to must not be negative")
+		}
+		if r.From == 0 && r.To == 0 {
+			return fmt.Errorf("ramp from and to can't both be 0")
+		}
+	}
+
+	if s := p.Sine; s != nil {
+		if s.Period <= 0 {
+			return fmt.Errorf("sine period must be positive")
+		}
+		if s.Amplitude < 0 || s.Amplitude > 1 {
+			return fmt.Errorf("sine amplitude must be between 0 and 1")
+		}
+	}
+
+	if err := validateDailyWeights(p.Daily); err != nil {
+		return err
+	}
+
+	for i, b := range p.Bursts {
+		if b.Multiplier < 0 {
+			return fmt.Errorf("burst %d: multiplier must not be negative", i)
+		}
+		if b.Duration <= 0 || b.Every <= 0 {
+			return fmt.Errorf("burst %d: duration and every must be positive", i)
+		}
+		if b.Duration > b.Every {
+			return fmt.Errorf("burst %d: duration must not be longer than every", i)
+		}
+		if b.Offset < 0 {
+			return fmt.Errorf("burst %d: offset must not be negative", i)
+		}
+	}
+	return nil
+}
+
+// validateDailyWeights checks a daily curve of hourly weights, if one is set
+func validateDailyWeights(weights []float64) error {
+	if len(weights) == 0 {
+		return nil
+	}
+	if len(weights) != 24 {
+		return fmt.Errorf("daily needs 24 hourly weights, got %d", len(weights))
+	}
+	total := 0.0
+	for _, weight := range weights {
+		if weight < 0 {
+			return fmt.Errorf("daily weights must not be negative")
+		}
+		total += weight
+	}
+	if total == 0 {
+		return fmt.Errorf("daily weights can't all be 0")
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// Scenario is an ordered sequence of templates, such as failed logins
+// followed by a successful one from the same IP address. Scenarios are mixed
+// into the other lines: every line either renders a template or starts a
+// scenario, selected by weight.
+type Scenario struct {
+	// Name identifies the scenario in errors
+	Name string `yaml:"name"`
+
+	// Weight determines the probability of starting the scenario, relative to
+	// the weights of the templates
+	Weight int `yaml:"weight"`
+
+	// Vars are rendered once when the scenario starts, and are available to
+	// the templates of its steps as {{var "name"}}
+	Vars map[string]string `yaml:"vars,omitempty"`
+
+	// Steps are the lines of the scenario, in order
+	Steps []ScenarioStep `yaml:"steps"`
+}
+
+// ScenarioStep renders a template one or more times as part of a scenario
+type ScenarioStep struct {
+	// Template is the name of the template to render
+	Template string `yaml:"template"`
+
+	// Repeat is the number of lines of the step. Defaults to 1.
+	Repeat int `yaml:"repeat,omitempty"`
+
+	// Delay is the time between the previous line of the scenario and every
+	// line of this step. The first line of a scenario is not delayed.
+	Delay time.Duration `yaml:"delay,omitempty"`
+}
+
+// validateScenarios checks that every scenario step refers to a named template
+func validateScenarios(scenarios []Scenario, templates []LogTemplate) error {
+	names := make(map[string]bool, len(templates))
+	for i, tpl := range templates {
+		if tpl.Name == "" {
+			continue
+		}
+		if names[tpl.Name] {
+			return fmt.Errorf("template %d: duplicate template name %s", i, tpl.Name)
+		}
+		names[tpl.Name] = true
+	}
+
+	for i, scenario := range scenarios {
+		name := scenario.Name
+		if name == "" {
+			name = fmt.Sprint(i)
+		}
+		if scenario.Weight < 0 {
+			return fmt.Errorf("scenario %s: weight must not be negative", name)
+		}
+		if len(scenario.Steps) == 0 {
+			return fmt.Errorf("scenario %s: no steps configured", name)
+		}
+		for j, step := range scenario.Steps {
+			if !names[step.Template] {
+				return fmt.Errorf("scenario %s: step %d refers to unknown template %q", name, j, step.Template)
+			}
+			if step.Repeat < 0 {
+				return fmt.Errorf("scenario %s: step %d repeat must not be negative", name, j)
+			}
+			if step.Delay < 0 {
+				return fmt.Errorf("scenario %s: step %d delay must not be negative", name, j)
+			}
+		}
+	}
+	return nil
+}
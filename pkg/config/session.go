@@ -0,0 +1,72 @@
+package config
+
+import "fmt"
+
+// SessionMarker marks a template as the first or last line of a session
+type SessionMarker string
+
+const (
+	// SessionStart opens a new session for the line, like a login
+	SessionStart SessionMarker = "start"
+	// SessionEnd ends the session of the line after rendering it, like a logout
+	SessionEnd SessionMarker = "end"
+)
+
+// Default session settings
+const (
+	DefaultSessionConcurrency = 10
+	DefaultSessionLifetime    = 20
+)
+
+// DefaultSessionAttributes are the attributes of a session when none are configured
+var DefaultSessionAttributes = map[string]string{
+	"user":       "{{Username}}",
+	"src_ip":     "{{IPv4Address}}",
+	"session_id": "{{UUID}}",
+	"user_agent": "{{UserAgent}}",
+}
+
+// SessionConfig configures the pool of live sessions templates draw from with
+// {{session "user"}}, so the same user, IP address and session ID show up
+// together across several lines.
+type SessionConfig struct {
+	// Concurrency is the number of sessions that are live at the same time.
+	// Defaults to 10.
+	Concurrency int `yaml:"concurrency,omitempty"`
+
+	// Lifetime is the average number of lines a session is used for. Every
+	// session ends after a random number of lines between half and one and a
+	// half times the lifetime, unless a template with session: end ends it
+	// first. Defaults to 20.
+	Lifetime int `yaml:"lifetime,omitempty"`
+
+	// Attributes are the sticky values of every session, rendered once when
+	// the session starts. Defaults to DefaultSessionAttributes.
+	Attributes map[string]string `yaml:"attributes,omitempty"`
+}
+
+// Validate checks the session settings
+func (s *SessionConfig) Validate() error {
+	if s.Concurrency < 0 {
+		return fmt.Errorf("session concurrency must not be negative")
+	}
+	if s.Lifetime < 0 {
+		return fmt.Errorf("session lifetime must not be negative")
+	}
+	for name, attribute := range s.Attributes {
+		if attribute == "" {
+			return fmt.Errorf("session attribute %s must not be empty", name)
+		}
+	}
+	return nil
+}
+
+// validateSessionMarker checks the session marker of a template
+func validateSessionMarker(marker SessionMarker) error {
+	switch marker {
+	case "", SessionStart, SessionEnd:
+		return nil
+	default:
+		return fmt.Errorf("unsupported session marker: %s", marker)
+	}
+}
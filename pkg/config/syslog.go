@@ -0,0 +1,159 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// syslogFacilities maps facility names to their RFC 5424 codes
+var syslogFacilities = map[string]int{
+	"kern":     0,
+	"user":     1,
+	"mail":     2,
+	"daemon":   3,
+	"auth":     4,
+	"syslog":   5,
+	"lpr":      6,
+	"news":     7,
+	"uucp":     8,
+	"cron":     9,
+	"authpriv": 10,
+	"ftp":      11,
+	"ntp":      12,
+	"security": 13,
+	"console":  14,
+	"clock":    15,
+	"local0":   16,
+	"local1":   17,
+	"local2":   18,
+	"local3":   19,
+	"local4":   20,
+	"local5":   21,
+	"local6":   22,
+	"local7":   23,
+}
+
+// syslogSeverities maps severity names, including the common log level
+// spellings used by applications, to their RFC 5424 codes
+var syslogSeverities = map[string]int{
+	"emerg":         0,
+	"emergency":     0,
+	"panic":         0,
+	"alert":         1,
+	"crit":          2,
+	"critical":      2,
+	"fatal":         2,
+	"err":           3,
+	"error":         3,
+	"warn":          4,
+	"warning":       4,
+	"notice":        5,
+	"info":          6,
+	"information":   6,
+	"informational": 6,
+	"debug":         7,
+	"trace":         7,
+}
+
+// SyslogFacility returns the code of a facility given by name (e.g. "local0")
+// or as a number. Matching ignores case.
+func SyslogFacility(name string) (int, bool) {
+	return lookupSyslogCode(syslogFacilities, name, 23)
+}
+
+// SyslogSeverity returns the code of a severity given by name (e.g. "warning"
+// or "ERROR") or as a number. Matching ignores case.
+func SyslogSeverity(name string) (int, bool) {
+	return lookupSyslogCode(syslogSeverities, name, 7)
+}
+
+func lookupSyslogCode(codes map[string]int, name string, maxCode int) (int, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if code, ok := codes[name]; ok {
+		return code, true
+	}
+	if code, err := strconv.Atoi(name); err == nil && code >= 0 && code <= maxCode {
+		return code, true
+	}
+	return 0, false
+}
+
+// Validate checks the syslog output settings, including the TCP settings
+// when Network is "tcp"
+func (c *SyslogOutputConfig) Validate() error {
+	if c.Address == "" {
+		return fmt.Errorf("address is required for syslog output")
+	}
+
+	switch c.Network {
+	case "", "udp":
+	case "tcp":
+		if err := c.TCPOutputConfig.Validate(); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unsupported syslog network: %s", c.Network)
+	}
+
+	switch c.Format {
+	case "", SyslogFormatRFC3164, SyslogFormatRFC5424:
+	default:
+		return fmt.Errorf("unsupported syslog format: %s", c.Format)
+	}
+	if err := c.validateHeader(); err != nil {
+		return err
+	}
+	if c.Facility != "" {
+		if _, ok := SyslogFacility(c.Facility); !ok {
+			return fmt.Errorf("unknown syslog facility: %s", c.Facility)
+		}
+	}
+	if c.Severity != "" {
+		if _, ok := SyslogSeverity(c.Severity); !ok {
+			return fmt.Errorf("unknown syslog severity: %s", c.Severity)
+		}
+	}
+	for from, to := range c.SeverityMap {
+		if _, ok := SyslogSeverity(to); !ok {
+			return fmt.Errorf("unknown syslog severity %s for %q in severity_map", to, from)
+		}
+	}
+	return nil
+}
+
+// validateHeader checks the header fields against the limits of RFC 5424,
+// and the TAG of RFC 3164, so every message can be parsed
+func (c *SyslogOutputConfig) validateHeader() error {
+	for _, field := range []struct {
+		name   string
+		value  string
+		maxLen int
+	}{
+		{"hostname", c.Hostname, 255},
+		{"app_name", c.AppName, 48},
+		{"procid", c.ProcID, 128},
+		{"msgid", c.MsgID, 32},
+	} {
+		if len(field.value) > field.maxLen {
+			return fmt.Errorf("syslog %s must be at most %d characters", field.name, field.maxLen)
+		}
+		for _, r := range field.value {
+			if r <= ' ' || r > '~' {
+				return fmt.Errorf("syslog %s must only contain printable ASCII characters without spaces", field.name)
+			}
+		}
+	}
+
+	if c.Format == SyslogFormatRFC3164 {
+		if len(c.AppName) > 32 {
+			return fmt.Errorf("syslog app_name must be at most 32 characters in rfc3164 format")
+		}
+		for _, r := range c.AppName {
+			if !('a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9') {
+				return fmt.Errorf("syslog app_name must only contain letters and digits in rfc3164 format")
+			}
+		}
+	}
+	return nil
+}
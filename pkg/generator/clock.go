@@ -0,0 +1,242 @@
+package generator
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/P1llus/genlog/pkg/config"
+	"github.com/brianvoe/gofakeit/v7"
+)
+
+// defaultRandomStart is the lower bound of random timestamps when no clock start is configured
+var defaultRandomStart = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// clock produces the timestamp of every generated line according to the
+// configured clock mode
+type clock struct {
+	cfg     config.ClockConfig
+	window  *backfillWindow // maps positions in the backfill window to timestamps
+	part    int             // the share of the backfill window this clock covers,
+	parts   int             // part out of parts equal shares
+	count   int             // number of lines spread over this clock's share
+	issued  int             // lines stamped so far in backfill mode
+	started time.Time       // real time the simulated clock started running
+	last    time.Time       // last simulated timestamp, so timestamps never go backwards
+	faker   *gofakeit.Faker // random source for random timestamps and jitter
+	now     func() time.Time
+	mu      sync.Mutex
+}
+
+// newClock creates the clock for count lines. Daily activity curves follow the
+// hours of the day in loc, and random timestamps and jitter are drawn from faker.
+func newClock(cfg config.ClockConfig, count int, loc *time.Location, faker *gofakeit.Faker) (*clock, error) {
+	if cfg.Mode == "" {
+		cfg.Mode = config.ClockModeRandom
+	}
+	if cfg.Speed == 0 {
+		cfg.Speed = 1
+	}
+	if cfg.Mode == config.ClockModeRandom && cfg.Start.IsZero() {
+		cfg.Start = defaultRandomStart
+	}
+
+	c := &clock{
+		cfg:   cfg,
+		parts: 1,
+		count: count,
+		faker: faker,
+		now:   time.Now,
+	}
+
+	if cfg.Mode == config.ClockModeBackfill {
+		if count <= 0 {
+			return nil, fmt.Errorf("backfill clock requires a count to spread the lines over")
+		}
+		c.resolveWindow()
+		c.window = newBackfillWindow(c.cfg.Start, c.cfg.End, cfg.Daily, loc)
+	}
+	return c, nil
+}
+
+// pinEnd fixes the end of a random clock without one to the start of the
+// current day in UTC, or to now when the clock starts later. Random timestamps
+// otherwise end at the time they are drawn, so seeded runs would differ.
+func (c *clock) pinEnd() {
+	if c.cfg.Mode != config.ClockModeRandom || !c.cfg.End.IsZero() {
+		return
+	}
+	now := c.now()
+	c.cfg.End = now.UTC().Truncate(24 * time.Hour)
+	if !c.cfg.End.After(c.cfg.Start) {
+		c.cfg.End = now
+	}
+}
+
+// resolveWindow fills in the start and end of the backfill window from the
+// configured window length, ending now when no end is given
+func (c *clock) resolveWindow() {
+	switch {
+	case c.cfg.End.IsZero() && !c.cfg.Start.IsZero() && c.cfg.Window > 0:
+		c.cfg.End = c.cfg.Start.Add(c.cfg.Window)
+	case c.cfg.End.IsZero():
+		c.cfg.End = c.now()
+	}
+	if c.cfg.Start.IsZero() {
+		c.cfg.Start = c.cfg.End.Add(-c.cfg.Window)
+	}
+}
+
+// split returns the clock for one of parts workers sharing count lines.
+// In backfill mode every worker gets its own consecutive share of the window,
+// so the timestamps of different workers never overlap and each worker writes
+// its lines in order. In random mode every worker draws timestamps from its
+// own faker. Realtime and simulated clocks are shared, so the timestamps of
+// all workers follow the same clock.
+func (c *clock) split(part, parts, count int, faker *gofakeit.Faker) *clock {
+	switch c.cfg.Mode {
+	case config.ClockModeBackfill:
+		return &clock{
+			cfg:    c.cfg,
+			window: c.window,
+			part:   part,
+			parts:  parts,
+			count:  count,
+			faker:  faker,
+			now:    c.now,
+		}
+	case config.ClockModeRandom:
+		return &clock{
+			cfg:   c.cfg,
+			parts: 1,
+			count: count,
+			faker: faker,
+			now:   c.now,
+		}
+	default:
+		return c
+	}
+}
+
+// next returns the timestamp for the next line
+func (c *clock) next() time.Time {
+	switch c.cfg.Mode {
+	case config.ClockModeRealtime:
+		return c.now()
+	case config.ClockModeSimulated:
+		return c.nextSimulated()
+	case config.ClockModeBackfill:
+		return c.nextBackfill()
+	default:
+		end := c.cfg.End
+		if end.IsZero() {
+			end = c.now()
+		}
+		return c.faker.DateRange(c.cfg.Start, end)
+	}
+}
+
+// nextSimulated advances the simulated clock by the real time passed since
+// it started, multiplied by the speed, and adds the jitter
+func (c *clock) nextSimulated() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	if c.started.IsZero() {
+		c.started = now
+		if c.cfg.Start.IsZero() {
+			c.cfg.Start = now
+		}
+	}
+
+	t := c.cfg.Start.Add(time.Duration(float64(now.Sub(c.started)) * c.cfg.Speed))
+	if c.cfg.Jitter > 0 {
+		t = t.Add(time.Duration(c.faker.IntRange(0, int(c.cfg.Jitter))))
+	}
+	if t.Before(c.last) {
+		t = c.last
+	}
+	c.last = t
+	return t
+}
+
+// nextBackfill returns the next of count evenly spread positions in this
+// clock's share of the backfill window. The end of the window is exclusive.
+func (c *clock) nextBackfill() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Lines beyond the count, for example when calling GenerateLogLine
+	// directly, stay at the last position
+	i := min(c.issued, c.count-1)
+	c.issued++
+
+	position := (float64(c.part) + float64(i)/float64(c.count)) / float64(c.parts)
+	return c.window.at(position)
+}
+
+// backfillWindow maps a position between 0 and 1 to a timestamp in a window,
+// so that evenly spread positions follow the daily activity curve
+type backfillWindow struct {
+	start, end time.Time
+	segments   []windowSegment // nil when activity is the same at every hour
+}
+
+// windowSegment is a part of the window within a single hour of the day
+type windowSegment struct {
+	start, end time.Time
+	from, to   float64 // cumulative activity at the start and end of the segment
+}
+
+func newBackfillWindow(start, end time.Time, daily []float64, loc *time.Location) *backfillWindow {
+	w := &backfillWindow{start: start, end: end}
+	if len(daily) != 24 {
+		return w
+	}
+
+	// Cut the window at every hour boundary and add up the activity
+	total := 0.0
+	for t := start; t.Before(end); {
+		local := t.In(loc)
+		next := time.Date(local.Year(), local.Month(), local.Day(), local.Hour()+1, 0, 0, 0, loc)
+		if !next.After(t) {
+			// Daylight saving time changes can make the next hour ambiguous
+			next = t.Add(time.Hour)
+		}
+		if next.After(end) {
+			next = end
+		}
+		activity := daily[local.Hour()] * next.Sub(t).Hours()
+		w.segments = append(w.segments, windowSegment{start: t, end: next, from: total, to: total + activity})
+		total += activity
+		t = next
+	}
+
+	// Without any activity in the window fall back to spreading lines evenly
+	if total == 0 {
+		w.segments = nil
+		return w
+	}
+	for i := range w.segments {
+		w.segments[i].from /= total
+		w.segments[i].to /= total
+	}
+	return w
+}
+
+// at returns the timestamp at position p, between 0 and 1, of the window
+func (w *backfillWindow) at(p float64) time.Time {
+	if w.segments == nil {
+		return w.start.Add(time.Duration(float64(w.end.Sub(w.start)) * p))
+	}
+
+	i := sort.Search(len(w.segments), func(i int) bool { return w.segments[i].to > p })
+	if i == len(w.segments) {
+		return w.end
+	}
+	s := w.segments[i]
+	fraction := (p - s.from) / (s.to - s.from)
+	return s.start.Add(time.Duration(float64(s.end.Sub(s.start)) * fraction))
+}
@@ -0,0 +1,339 @@
+package generator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/P1llus/genlog/pkg/config"
+	"github.com/brianvoe/gofakeit/v7"
+)
+
+func TestClockSimulated(t *testing.T) {
+	start := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	wall := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	c, err := newClock(config.ClockConfig{
+		Mode:  config.ClockModeSimulated,
+		Start: start,
+		Speed: 60,
+	}, 0, time.UTC, gofakeit.New(12345))
+	if err != nil {
+		t.Fatalf("newClock failed: %v", err)
+	}
+	c.now = func() time.Time { return wall }
+
+	if got := c.next(); !got.Equal(start) {
+		t.Errorf("First timestamp = %v, want %v", got, start)
+	}
+
+	// One real second is a simulated minute at speed 60
+	wall = wall.Add(time.Second)
+	if got, want := c.next(), start.Add(time.Minute); !got.Equal(want) {
+		t.Errorf("Timestamp after 1s = %v, want %v", got, want)
+	}
+}
+
+func TestClockSimulatedJitterStaysOrdered(t *testing.T) {
+	wall := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	c, err := newClock(config.ClockConfig{
+		Mode:   config.ClockModeSimulated,
+		Jitter: time.Second,
+	}, 0, time.UTC, gofakeit.New(12345))
+	if err != nil {
+		t.Fatalf("newClock failed: %v", err)
+	}
+	c.now = func() time.Time { return wall }
+
+	last := c.next()
+	if last.Before(wall) || last.After(wall.Add(time.Second)) {
+		t.Errorf("Timestamp %v outside of the jitter from the start %v", last, wall)
+	}
+	for i := 0; i < 100; i++ {
+		wall = wall.Add(time.Millisecond)
+		next := c.next()
+		if next.Before(last) {
+			t.Fatalf("Timestamp went backwards from %v to %v", last, next)
+		}
+		last = next
+	}
+}
+
+func TestClockBackfill(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+
+	c, err := newClock(config.ClockConfig{
+		Mode:  config.ClockModeBackfill,
+		Start: start,
+		End:   end,
+	}, 4, time.UTC, gofakeit.New(12345))
+	if err != nil {
+		t.Fatalf("newClock failed: %v", err)
+	}
+
+	// The end of the window is exclusive
+	for i := 0; i < 4; i++ {
+		want := start.Add(time.Duration(i) * 15 * time.Minute)
+		if got := c.next(); !got.Equal(want) {
+			t.Errorf("Timestamp %d = %v, want %v", i, got, want)
+		}
+	}
+	if got, want := c.next(), start.Add(45*time.Minute); !got.Equal(want) {
+		t.Errorf("Timestamp beyond count = %v, want the last position %v", got, want)
+	}
+
+	if _, err := newClock(config.ClockConfig{Mode: config.ClockModeBackfill, Start: start, End: end}, 0, time.UTC, gofakeit.New(12345)); err == nil {
+		t.Error("Expected error for backfill without a count, got nil")
+	}
+}
+
+func TestClockBackfillWindowEndingNow(t *testing.T) {
+	before := time.Now()
+	c, err := newClock(config.ClockConfig{
+		Mode:   config.ClockModeBackfill,
+		Window: 7 * 24 * time.Hour,
+	}, 100, time.UTC, gofakeit.New(12345))
+	if err != nil {
+		t.Fatalf("newClock failed: %v", err)
+	}
+
+	if c.cfg.End.Before(before) || c.cfg.End.After(time.Now()) {
+		t.Errorf("Window end %v is not the current time", c.cfg.End)
+	}
+	if got := c.cfg.End.Sub(c.cfg.Start); got != 7*24*time.Hour {
+		t.Errorf("Window length = %v, want 168h", got)
+	}
+}
+
+func TestClockBackfillSplitsWorkers(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	c, err := newClock(config.ClockConfig{
+		Mode:  config.ClockModeBackfill,
+		Start: start,
+		End:   start.Add(4 * time.Hour),
+	}, 8, time.UTC, gofakeit.New(12345))
+	if err != nil {
+		t.Fatalf("newClock failed: %v", err)
+	}
+
+	// Two workers with four lines each get an hour apart and two hours each
+	first, second := c.split(0, 2, 4, c.faker), c.split(1, 2, 4, c.faker)
+	for i := 0; i < 4; i++ {
+		offset := time.Duration(i) * 30 * time.Minute
+		if got, want := first.next(), start.Add(offset); !got.Equal(want) {
+			t.Errorf("First worker timestamp %d = %v, want %v", i, got, want)
+		}
+		if got, want := second.next(), start.Add(2*time.Hour+offset); !got.Equal(want) {
+			t.Errorf("Second worker timestamp %d = %v, want %v", i, got, want)
+		}
+	}
+}
+
+func TestBackfillWindowDaily(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(24 * time.Hour)
+
+	// All activity happens between 09:00 and 11:00, three times as much in the second hour
+	daily := make([]float64, 24)
+	daily[9] = 1
+	daily[10] = 3
+	w := newBackfillWindow(start, end, daily, time.UTC)
+
+	tests := []struct {
+		position float64
+		want     time.Time
+	}{
+		{0, start.Add(9 * time.Hour)},
+		{0.125, start.Add(9*time.Hour + 30*time.Minute)},
+		{0.25, start.Add(10 * time.Hour)},
+		{0.625, start.Add(10*time.Hour + 30*time.Minute)},
+	}
+	for _, tt := range tests {
+		if got := w.at(tt.position); !got.Equal(tt.want) {
+			t.Errorf("at(%v) = %v, want %v", tt.position, got, tt.want)
+		}
+	}
+}
+
+func TestClockRandomRange(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(24 * time.Hour)
+
+	c, err := newClock(config.ClockConfig{Start: start, End: end}, 0, time.UTC, gofakeit.New(12345))
+	if err != nil {
+		t.Fatalf("newClock failed: %v", err)
+	}
+	for i := 0; i < 100; i++ {
+		if got := c.next(); got.Before(start) || got.After(end) {
+			t.Fatalf("Random timestamp %v outside of %v - %v", got, start, end)
+		}
+	}
+}
+
+func TestClockPinEnd(t *testing.T) {
+	wall := time.Date(2025, 6, 1, 15, 30, 0, 0, time.UTC)
+	midnight := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		cfg  config.ClockConfig
+		want time.Time
+	}{
+		{
+			name: "default random clock",
+			cfg:  config.ClockConfig{},
+			want: midnight,
+		},
+		{
+			name: "random clock starting today",
+			cfg:  config.ClockConfig{Start: midnight.Add(time.Hour)},
+			want: wall,
+		},
+		{
+			name: "configured end",
+			cfg:  config.ClockConfig{End: end},
+			want: end,
+		},
+		{
+			name: "realtime clock",
+			cfg:  config.ClockConfig{Mode: config.ClockModeRealtime},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := newClock(tt.cfg, 0, time.UTC, gofakeit.New(12345))
+			if err != nil {
+				t.Fatalf("newClock failed: %v", err)
+			}
+			c.now = func() time.Time { return wall }
+			c.pinEnd()
+			if !c.cfg.End.Equal(tt.want) {
+				t.Errorf("End = %v, want %v", c.cfg.End, tt.want)
+			}
+		})
+	}
+}
+
+func TestSharedClockHasItsOwnFaker(t *testing.T) {
+	cfg := &config.Config{
+		Templates: []config.LogTemplate{{Template: "line", Weight: 1}},
+		Clock:     config.ClockConfig{Mode: config.ClockModeSimulated, Jitter: time.Second},
+		Seed:      12345,
+	}
+
+	r, err := NewRenderer(cfg, 0)
+	if err != nil {
+		t.Fatalf("NewRenderer failed: %v", err)
+	}
+
+	// Workers share the simulated clock, so its jitter must not be drawn from
+	// the faker of the renderer or of a worker
+	worker := gofakeit.New(1)
+	shared := r.clock.split(0, 1, 0, worker)
+	if shared.faker == r.faker || shared.faker == worker {
+		t.Error("Expected the shared clock to have its own faker")
+	}
+}
+
+func TestLineTimestampsMatch(t *testing.T) {
+	cfg := &config.Config{
+		Templates: []config.LogTemplate{
+			{
+				Template: `{{FormattedDate "2006-01-02T15:04:05.000000Z07:00"}} {{(Now).Format "2006-01-02T15:04:05.000000Z07:00"}}`,
+				Weight:   1,
+			},
+		},
+		Outputs: []config.OutputConfig{
+			{
+				Type:    config.OutputTypeStdout,
+				Workers: 1,
+			},
+		},
+		Clock: config.ClockConfig{Mode: config.ClockModeRealtime},
+	}
+
+	gen, err := NewGenerator(cfg, 0)
+	if err != nil {
+		t.Fatalf("NewGenerator failed: %v", err)
+	}
+
+	line, err := gen.GenerateLogLine()
+	if err != nil {
+		t.Fatalf("GenerateLogLine failed: %v", err)
+	}
+	parts := strings.Split(line, " ")
+	if len(parts) != 2 || parts[0] != parts[1] {
+		t.Errorf("Expected FormattedDate and Now to return the same time within a line, got %q", line)
+	}
+}
+
+func TestBackfillWorkerFilesSorted(t *testing.T) {
+	// Create a temporary directory for test files
+	tmpDir, err := os.MkdirTemp("", "generator-test-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	cfg := &config.Config{
+		Templates: []config.LogTemplate{
+			{
+				Template: `{{FormattedDate "2006-01-02T15:04:05.000Z07:00"}} test`,
+				Weight:   1,
+			},
+		},
+		Outputs: []config.OutputConfig{
+			{
+				Type:      config.OutputTypeFile,
+				Workers:   2,
+				BatchSize: 7,
+				Config: map[string]interface{}{
+					"filename": filepath.Join(tmpDir, "backfill.log"),
+				},
+			},
+		},
+		Clock: config.ClockConfig{
+			Mode:  config.ClockModeBackfill,
+			Start: start,
+			End:   start.Add(24 * time.Hour),
+		},
+	}
+
+	gen, err := NewGenerator(cfg, 100)
+	if err != nil {
+		t.Fatalf("NewGenerator failed: %v", err)
+	}
+	gen.Start()
+	<-gen.Done()
+	if err := gen.Stop(); err != nil {
+		t.Fatalf("Stop failed: %v", err)
+	}
+
+	var previousLast string
+	for i := 0; i < 2; i++ {
+		data, err := os.ReadFile(filepath.Join(tmpDir, fmt.Sprintf("backfill_worker%d.log", i)))
+		if err != nil {
+			t.Fatalf("Failed to read worker file: %v", err)
+		}
+		lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+		if len(lines) != 50 {
+			t.Fatalf("Worker %d wrote %d lines, want 50", i, len(lines))
+		}
+		for j := 1; j < len(lines); j++ {
+			if lines[j] < lines[j-1] {
+				t.Fatalf("Worker %d lines out of order: %q before %q", i, lines[j-1], lines[j])
+			}
+		}
+		if lines[0] <= previousLast {
+			t.Errorf("Worker %d starts at %q, overlapping the previous worker ending at %q", i, lines[0], previousLast)
+		}
+		previousLast = lines[len(lines)-1]
+	}
+}
@@ -0,0 +1,125 @@
+package generator
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/P1llus/genlog/pkg/config"
+	"github.com/brianvoe/gofakeit/v7"
+)
+
+// poissonNormalThreshold is the lambda above which Poisson numbers are
+// approximated by a normal distribution, as multiplying uniform numbers
+// becomes slow and imprecise for large means
+const poissonNormalThreshold = 30
+
+// distribution generates random numbers for a custom type configured with a
+// numeric distribution. Numbers are drawn from faker, so they follow the
+// configured seed.
+type distribution struct {
+	cfg    config.Distribution
+	faker  *gofakeit.Faker
+	layout string    // fmt verb used to render numbers
+	ranks  []float64 // cumulative probabilities of the zipf ranks 1 to N
+}
+
+func newDistribution(cfg config.Distribution, faker *gofakeit.Faker) *distribution {
+	d := &distribution{cfg: cfg, faker: faker, layout: cfg.Format}
+	if d.layout == "" {
+		d.layout = "%.2f"
+		if cfg.Type == config.DistributionZipf || cfg.Type == config.DistributionPoisson {
+			d.layout = "%.0f"
+		}
+	}
+
+	if cfg.Type == config.DistributionZipf {
+		d.ranks = make([]float64, cfg.N)
+		total := 0.0
+		for k := 1; k <= cfg.N; k++ {
+			total += 1 / math.Pow(float64(k), cfg.S)
+			d.ranks[k-1] = total
+		}
+		for i := range d.ranks {
+			d.ranks[i] /= total
+		}
+		d.ranks[cfg.N-1] = 1
+	}
+	return d
+}
+
+// withFaker returns a copy of d drawing numbers from faker. The copy shares
+// the zipf ranks, which are only read after they are built.
+func (d *distribution) withFaker(faker *gofakeit.Faker) *distribution {
+	c := *d
+	c.faker = faker
+	return &c
+}
+
+// format returns the next number rendered with the configured format
+func (d *distribution) format() string {
+	return fmt.Sprintf(d.layout, d.next())
+}
+
+// next returns the next number, clamped to the configured min and max
+func (d *distribution) next() float64 {
+	v := d.sample()
+	if d.cfg.Min != nil && v < *d.cfg.Min {
+		v = *d.cfg.Min
+	}
+	if d.cfg.Max != nil && v > *d.cfg.Max {
+		v = *d.cfg.Max
+	}
+	return v
+}
+
+// sample draws a number from the configured distribution
+func (d *distribution) sample() float64 {
+	switch d.cfg.Type {
+	case config.DistributionNormal:
+		return d.cfg.Mean + d.cfg.StdDev*d.standardNormal()
+	case config.DistributionExponential:
+		return -math.Log(d.positiveUniform()) / d.cfg.Rate
+	case config.DistributionLognormal:
+		return math.Exp(d.cfg.Mu + d.cfg.Sigma*d.standardNormal())
+	case config.DistributionPareto:
+		return d.cfg.Scale / math.Pow(d.positiveUniform(), 1/d.cfg.Alpha)
+	case config.DistributionZipf:
+		return float64(sort.SearchFloat64s(d.ranks, d.faker.Float64()) + 1)
+	case config.DistributionPoisson:
+		return d.poisson()
+	case config.DistributionUniform:
+		return *d.cfg.Min + d.faker.Float64()*(*d.cfg.Max-*d.cfg.Min)
+	default:
+		return 0
+	}
+}
+
+// positiveUniform returns a uniform random number in (0, 1], which is safe to
+// take the logarithm of
+func (d *distribution) positiveUniform() float64 {
+	return 1 - d.faker.Float64()
+}
+
+// standardNormal returns a normally distributed number with mean 0 and
+// standard deviation 1, using the Box-Muller transform
+func (d *distribution) standardNormal() float64 {
+	return math.Sqrt(-2*math.Log(d.positiveUniform())) * math.Cos(2*math.Pi*d.faker.Float64())
+}
+
+// poisson returns a Poisson distributed number with mean lambda
+func (d *distribution) poisson() float64 {
+	lambda := d.cfg.Lambda
+	if lambda > poissonNormalThreshold {
+		return math.Max(0, math.Round(lambda+math.Sqrt(lambda)*d.standardNormal()))
+	}
+
+	// Count how many uniform numbers can be multiplied before the product
+	// drops below e^-lambda
+	limit := math.Exp(-lambda)
+	k := 0.0
+	for p := d.faker.Float64(); p > limit; p *= d.faker.Float64() {
+		k++
+	}
+	return k
+}
@@ -0,0 +1,162 @@
+package generator
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/P1llus/genlog/pkg/config"
+	"github.com/brianvoe/gofakeit/v7"
+)
+
+func TestDistributionMeans(t *testing.T) {
+	faker := gofakeit.New(12345)
+
+	low, high := 10.0, 20.0
+	tests := []struct {
+		name      string
+		cfg       config.Distribution
+		want      float64
+		tolerance float64
+	}{
+		{"normal", config.Distribution{Type: config.DistributionNormal, Mean: 100, StdDev: 15}, 100, 1},
+		{"exponential", config.Distribution{Type: config.DistributionExponential, Rate: 0.5}, 2, 0.1},
+		{"lognormal", config.Distribution{Type: config.DistributionLognormal, Mu: 1, Sigma: 0.5}, math.Exp(1.125), 0.1},
+		{"pareto", config.Distribution{Type: config.DistributionPareto, Alpha: 3, Scale: 2}, 3, 0.1},
+		{"poisson", config.Distribution{Type: config.DistributionPoisson, Lambda: 4}, 4, 0.1},
+		{"large poisson", config.Distribution{Type: config.DistributionPoisson, Lambda: 500}, 500, 2},
+		{"uniform", config.Distribution{Type: config.DistributionUniform, Min: &low, Max: &high}, 15, 0.2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newDistribution(tt.cfg, faker)
+			sum := 0.0
+			for i := 0; i < 20000; i++ {
+				sum += d.next()
+			}
+			if mean := sum / 20000; math.Abs(mean-tt.want) > tt.tolerance {
+				t.Errorf("Mean = %f, want %f ± %f", mean, tt.want, tt.tolerance)
+			}
+		})
+	}
+}
+
+func TestDistributionZipf(t *testing.T) {
+	faker := gofakeit.New(12345)
+
+	d := newDistribution(config.Distribution{Type: config.DistributionZipf, S: 1, N: 3}, faker)
+	counts := make(map[float64]int)
+	for i := 0; i < 11000; i++ {
+		counts[d.next()]++
+	}
+
+	// With s = 1 the ranks 1, 2 and 3 are picked in the ratio 6:3:2
+	if len(counts) != 3 {
+		t.Fatalf("Expected ranks 1 to 3, got %v", counts)
+	}
+	if ratio := float64(counts[1]) / float64(counts[3]); ratio < 2.7 || ratio > 3.3 {
+		t.Errorf("Ratio of rank 1 to rank 3 = %f, want 3", ratio)
+	}
+}
+
+func TestDistributionSharedBetweenSources(t *testing.T) {
+	cfg := &config.Config{
+		Templates: []config.LogTemplate{{Template: "{{status}}", Weight: 1}},
+		WeightedCustomTypes: map[string]config.CustomType{
+			"status": {Distribution: &config.Distribution{Type: config.DistributionZipf, S: 1, N: 1000}},
+		},
+	}
+
+	r, err := NewRenderer(cfg, 0)
+	if err != nil {
+		t.Fatalf("NewRenderer failed: %v", err)
+	}
+	d, ok := r.distributions["status"]
+	if !ok || len(d.ranks) != 1000 {
+		t.Fatalf("Expected the zipf ranks of status to be built once")
+	}
+
+	// Every source draws from its own faker, but reads the same ranks
+	first, second := d.withFaker(gofakeit.New(1)), d.withFaker(gofakeit.New(2))
+	if first.faker == second.faker {
+		t.Error("Expected every copy to have its own faker")
+	}
+	if &first.ranks[0] != &d.ranks[0] || &second.ranks[0] != &d.ranks[0] {
+		t.Error("Expected the zipf ranks to be shared")
+	}
+	if _, err := r.GenerateLogLine(); err != nil {
+		t.Errorf("GenerateLogLine failed: %v", err)
+	}
+}
+
+func TestDistributionClampAndFormat(t *testing.T) {
+	low, high := 1.0, 30000.0
+	d := newDistribution(config.Distribution{
+		Type:   config.DistributionLognormal,
+		Mu:     4,
+		Sigma:  3,
+		Min:    &low,
+		Max:    &high,
+		Format: "%.0f",
+	}, gofakeit.New(12345))
+
+	for i := 0; i < 1000; i++ {
+		value := d.format()
+		if strings.Contains(value, ".") {
+			t.Fatalf("Value %q not formatted as a whole number", value)
+		}
+		if v := d.next(); v < low || v > high {
+			t.Fatalf("Value %f outside of %f - %f", v, low, high)
+		}
+	}
+
+	if got := newDistribution(config.Distribution{Type: config.DistributionNormal, StdDev: 1}, nil).layout; got != "%.2f" {
+		t.Errorf("Default format = %q, want %%.2f", got)
+	}
+}
+
+func TestDistributionCustomTypeSeed(t *testing.T) {
+	cfg := &config.Config{
+		Templates: []config.LogTemplate{
+			{
+				Template: "{{latency}} {{status}}",
+				Weight:   1,
+			},
+		},
+		WeightedCustomTypes: map[string]config.CustomType{
+			"latency": {Distribution: &config.Distribution{Type: config.DistributionExponential, Rate: 0.01}},
+			"status":  {Distribution: &config.Distribution{Type: config.DistributionZipf, S: 1.2, N: 10}},
+		},
+		Outputs: []config.OutputConfig{
+			{
+				Type:    config.OutputTypeStdout,
+				Workers: 1,
+			},
+		},
+		Seed: 12345,
+	}
+
+	generate := func() []string {
+		gen, err := NewGenerator(cfg, 0)
+		if err != nil {
+			t.Fatalf("NewGenerator failed: %v", err)
+		}
+		var lines []string
+		for i := 0; i < 10; i++ {
+			line, err := gen.GenerateLogLine()
+			if err != nil {
+				t.Fatalf("GenerateLogLine failed: %v", err)
+			}
+			lines = append(lines, line)
+		}
+		return lines
+	}
+
+	first, second := generate(), generate()
+	for i := range first {
+		if first[i] != second[i] {
+			t.Errorf("Line %d differs with the same seed: %q and %q", i, first[i], second[i])
+		}
+	}
+}
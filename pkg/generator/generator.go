@@ -4,9 +4,9 @@
 package generator
 
 import (
+	"errors"
 	"fmt"
 	"sync"
-	"text/template"
 	"time"
 
 	"github.com/P1llus/genlog/pkg/config"
@@ -15,53 +15,46 @@ import (
 )
 
 // Generator is responsible for generating fake log entries
-// based on the provided configuration. It handles template selection,
-// random value generation, and output management.
+// based on the provided configuration. It runs a Renderer, which handles
+// template selection and random value generation, with the configured
+// outputs and their workers.
 type Generator struct {
-	config      *config.Config
-	funcMap     template.FuncMap
-	totalWeight int
-	workers     []*output.Worker
-	stopChan    chan struct{}
-	wg          sync.WaitGroup
-	maxCount    int
-	doneChan    chan struct{} // Channel to signal completion
+	*Renderer
+	workers  []*output.Worker
+	stopChan chan struct{}
+	stopOnce sync.Once // stopChan is closed by Stop or when the duration has passed
+	wg       sync.WaitGroup
+	maxCount int
+	doneChan chan struct{} // Channel to signal completion
+	err      error         // first error that stopped a worker
+	errOnce  sync.Once
 }
 
 // NewGenerator creates a new log generator with the given configuration.
-// It initializes the function map for template rendering and calculates
-// the total template weight for weighted random selection.
+// It creates the renderer for the templates, see NewRenderer, and initializes
+// the outputs and their workers.
 //
-// The function map includes all custom types from the configuration,
-// making them available as placeholders in templates.
+// Every generator has its own random source, so generators don't affect each
+// other. Every worker gets its own random source derived from it, which makes
+// the output of every worker reproducible when a seed is configured.
 func NewGenerator(cfg *config.Config, maxCount int) (*Generator, error) {
 	err := cfg.Validate()
 	if err != nil {
 		return nil, fmt.Errorf("error validating config: %w", err)
 	}
-	// Set the seed for randomization if provided
-	if cfg.Seed != 0 {
-		gofakeit.Seed(cfg.Seed)
-	}
 
-	// Calculate total weight for template selection
-	totalWeight := 0
-	for _, tpl := range cfg.Templates {
-		totalWeight += tpl.Weight
+	renderer, err := newRenderer(cfg, maxCount)
+	if err != nil {
+		return nil, err
 	}
 
-	// Create the generator instance
 	g := &Generator{
-		config:      cfg,
-		totalWeight: totalWeight,
-		stopChan:    make(chan struct{}),
-		doneChan:    make(chan struct{}),
-		maxCount:    maxCount,
+		Renderer: renderer,
+		stopChan: make(chan struct{}),
+		doneChan: make(chan struct{}),
+		maxCount: maxCount,
 	}
 
-	// Initialize the function map for template rendering
-	g.funcMap = g.createFuncMap(cfg.CustomTypes)
-
 	// Initialize outputs and workers
 	if err := g.initializeOutputs(); err != nil {
 		return nil, fmt.Errorf("error initializing outputs: %w", err)
@@ -72,7 +65,31 @@ func NewGenerator(cfg *config.Config, maxCount int) (*Generator, error) {
 
 // initializeOutputs sets up all configured outputs and their workers
 func (g *Generator) initializeOutputs() error {
+	// The global limiter is shared by every worker of every output
+	var globalLimiter *output.RateLimiter
+	if g.config.Rate > 0 {
+		globalLimiter = output.NewRateLimiter(g.config.Rate, g.config.Burst, g.config.LoadProfile, g.location)
+	}
+
 	for _, outputCfg := range g.config.Outputs {
+		var limiters []*output.RateLimiter
+		if globalLimiter != nil {
+			limiters = append(limiters, globalLimiter)
+		}
+		if outputCfg.Rate > 0 {
+			limiters = append(limiters, output.NewRateLimiter(outputCfg.Rate, outputCfg.Burst, outputCfg.LoadProfile, g.location))
+		}
+
+		// Templates defined as fields are rendered as JSON, so only other
+		// encoders encode them again
+		var encoder output.Encoder
+		if outputCfg.Encoder != nil && outputCfg.Encoder.Type != config.EncoderJSON {
+			var err error
+			if encoder, err = output.NewEncoder(*outputCfg.Encoder); err != nil {
+				return fmt.Errorf("error creating encoder for output %s: %w", outputCfg.Type, err)
+			}
+		}
+
 		// Calculate max count per worker
 		maxCountPerWorker := 0
 		if g.maxCount > 0 {
@@ -90,7 +107,17 @@ func (g *Generator) initializeOutputs() error {
 				return fmt.Errorf("error creating output %s: %w", outputCfg.Type, err)
 			}
 
-			worker := output.NewWorker(out, g, outputCfg.BatchSize, maxCountPerWorker, g.stopChan)
+			faker := gofakeit.New(g.faker.Uint64())
+			clock := g.clock.split(i, outputCfg.Workers, maxCountPerWorker, faker)
+			source, err := g.newWorkerSource(faker, clock)
+			if err != nil {
+				return err
+			}
+			worker := output.NewWorker(out, source, outputCfg.BatchSize, maxCountPerWorker, g.stopChan)
+			worker.SetRateLimiters(limiters...)
+			if encoder != nil {
+				worker.SetEncoder(encoder)
+			}
 			g.workers = append(g.workers, worker)
 		}
 	}
@@ -98,8 +125,10 @@ func (g *Generator) initializeOutputs() error {
 	return nil
 }
 
-// Done returns a channel that is closed when the generator has completed
-// generating the requested number of logs
+// Done returns a channel that is closed when the generator has completed,
+// either by generating the requested number of logs, by running for the
+// configured duration, by being stopped or because a worker failed to
+// generate a line. Stop returns the error in the latter case.
 func (g *Generator) Done() chan struct{} {
 	return g.doneChan
 }
@@ -111,111 +140,65 @@ func (g *Generator) Start() {
 		go func(w *output.Worker) {
 			defer g.wg.Done()
 			w.Start()
+			// A worker that fails stops the others, so generation ends
+			// instead of continuing with fewer workers
+			if err := w.Err(); err != nil {
+				g.errOnce.Do(func() { g.err = err })
+				g.stopWorkers()
+			}
 		}(worker)
 	}
 
-	// If we have a max count, start a goroutine to monitor completion
-	if g.maxCount > 0 {
+	// Stop the workers once the duration has passed, unless they finish first
+	if g.config.Duration > 0 {
 		go func() {
-			g.wg.Wait()
-			close(g.doneChan)
+			timer := time.NewTimer(g.config.Duration)
+			defer timer.Stop()
+			select {
+			case <-timer.C:
+				g.stopWorkers()
+			case <-g.stopChan:
+			case <-g.doneChan:
+			}
 		}()
 	}
-}
-
-// Stop gracefully stops all workers and closes outputs
-func (g *Generator) Stop() error {
-	close(g.stopChan)
-	g.wg.Wait()
-
-	// Close all outputs
-	for _, worker := range g.workers {
-		if err := worker.Output.Close(); err != nil {
-			return fmt.Errorf("error closing output: %w", err)
-		}
-	}
 
-	return nil
+	// Monitor completion, whichever way the workers end
+	go func() {
+		g.wg.Wait()
+		close(g.doneChan)
+	}()
 }
 
-// selectWeightedTemplate selects a random template index based on the weights.
-// Templates with higher weights have a proportionally higher chance of being selected.
-func (g *Generator) selectWeightedTemplate() int {
-	if g.totalWeight <= 0 || len(g.config.Templates) == 0 {
-		return 0
-	}
-
-	r := gofakeit.IntRange(0, g.totalWeight-1)
-	sum := 0
-	for i, tpl := range g.config.Templates {
-		sum += tpl.Weight
-		if r < sum {
-			return i
+// stopWorkers signals all workers to flush their last batch and return.
+// Outputs that retry failed writes are canceled, so workers waiting for a
+// destination that can't be reached return as well.
+func (g *Generator) stopWorkers() {
+	g.stopOnce.Do(func() {
+		close(g.stopChan)
+		for _, worker := range g.workers {
+			if out, ok := worker.Output.(output.Canceler); ok {
+				out.Cancel()
+			}
 		}
-	}
-	return 0
+	})
 }
 
-// createFuncMap creates a map of functions that can be used in templates.
-// The map includes:
-// 1. All custom types from the configuration, each as a function returning a random value
-// 2. Built-in helper functions like FormattedDate
-//
-// Note: The addLookupFunc functionality of gofakeit is not available when rendering
-// inside go templates. This is why we have to create a map of the function names along
-// with the function used to generate the random value.
-func (g *Generator) createFuncMap(customTypes map[string][]string) template.FuncMap {
-	funcMap := make(map[string]any)
-
-	// Add each custom type as a function that returns a random value from its slice
-	for typeName, values := range customTypes {
-		// Create a function to properly capture the values for each custom type
-		funcMap[typeName] = g.createRandomValueFunc(values)
-	}
-
-	// Add built-in helper functions
-	funcMap["FormattedDate"] = func(format string) string {
-		// Generate a random date within a reasonable range
-		minDate := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
-		maxDate := time.Now() // Current time
+// Stop gracefully stops all workers and closes outputs. It returns the error
+// that stopped generation early, such as a template that fails to render,
+// together with any error closing the outputs.
+func (g *Generator) Stop() error {
+	g.stopWorkers()
+	g.wg.Wait()
 
-		randomDate := gofakeit.DateRange(minDate, maxDate)
-		return randomDate.Format(format)
-	}
+	errs := []error{g.err}
 
-	return funcMap
-}
-
-// createRandomValueFunc creates a function that returns a random value from the given slice.
-// This is used to translate configured custom types to a funcMap for the template functions.
-func (g *Generator) createRandomValueFunc(values []string) func() string {
-	return func() string {
-		if len(values) == 0 {
-			return ""
+	// Close all outputs
+	for _, worker := range g.workers {
+		if err := worker.Output.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("error closing output: %w", err))
 		}
-		index := gofakeit.IntRange(0, len(values)-1)
-		return values[index]
-	}
-}
-
-// GenerateLogLine generates a single log line using a randomly selected template.
-// This is useful for generating log lines programmatically without writing to a file,
-// such as when streaming logs directly to another system.
-func (g *Generator) GenerateLogLine() (string, error) {
-	// First check if we have any templates
-	if len(g.config.Templates) == 0 {
-		return "", fmt.Errorf("no templates available")
-	}
-
-	templateIdx := g.selectWeightedTemplate()
-	selectedTemplate := g.config.Templates[templateIdx].Template
-
-	logLine, err := gofakeit.Template(selectedTemplate, &gofakeit.TemplateOptions{
-		Funcs: g.funcMap,
-	})
-	if err != nil {
-		return "", fmt.Errorf("error generating log line: %w", err)
 	}
 
-	return logLine, nil
+	return errors.Join(errs...)
 }
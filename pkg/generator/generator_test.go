@@ -1,13 +1,22 @@
 package generator
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
 	"os"
 	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/P1llus/genlog/pkg/config"
 	"github.com/P1llus/genlog/pkg/output"
+	"github.com/brianvoe/gofakeit/v7"
 )
 
 func TestNewGenerator(t *testing.T) {
@@ -98,6 +107,209 @@ func TestGenerateLogLine(t *testing.T) {
 	}
 }
 
+func TestGenerateEvent(t *testing.T) {
+	// Create a temporary directory for test files
+	tmpDir, err := os.MkdirTemp("", "generator-test-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	cfg := &config.Config{
+		Templates: []config.LogTemplate{
+			{
+				Template: "[{{level}}] {{Field \"user\" \"alice\"}} {{message}}",
+				Weight:   1,
+			},
+		},
+		CustomTypes: map[string][]string{
+			"level":   {"INFO", "ERROR"},
+			"message": {"test message"},
+		},
+		Outputs: []config.OutputConfig{
+			{
+				Type:    config.OutputTypeFile,
+				Workers: 1,
+				Config: map[string]interface{}{
+					"filename": filepath.Join(tmpDir, "test.log"),
+				},
+			},
+		},
+	}
+
+	gen, err := NewGenerator(cfg, 1)
+	if err != nil {
+		t.Fatalf("NewGenerator failed: %v", err)
+	}
+
+	event, err := gen.GenerateEvent()
+	if err != nil {
+		t.Fatalf("GenerateEvent failed: %v", err)
+	}
+
+	want := "[" + event.Values["level"] + "] alice test message"
+	if event.Line != want {
+		t.Errorf("Event line = %q, want %q", event.Line, want)
+	}
+	if event.Values["user"] != "alice" {
+		t.Errorf("Expected Field value alice, got %q", event.Values["user"])
+	}
+	if event.Values["message"] != "test message" {
+		t.Errorf("Expected custom type value to be recorded, got %q", event.Values["message"])
+	}
+}
+
+func TestTemplateVars(t *testing.T) {
+	cfg := &config.Config{
+		Templates: []config.LogTemplate{
+			{
+				Template: `user={{var "user"}} uid={{var "uid"}} home=/home/{{var "user"}} shell={{var "user"}}@{{var "host"}}`,
+				Weight:   1,
+				Vars: map[string]string{
+					"user": "{{username}}",
+					"uid":  "{{Number 1000 60000}}",
+					"host": "{{host}}",
+				},
+			},
+		},
+		CustomTypes: map[string][]string{
+			"username": {"alice", "bob", "carol", "dave"},
+			"host":     {"web-01", "web-02", "db-01"},
+		},
+		Outputs: []config.OutputConfig{
+			{
+				Type:    config.OutputTypeStdout,
+				Workers: 1,
+			},
+		},
+		Seed: 12345,
+	}
+
+	gen, err := NewGenerator(cfg, 0)
+	if err != nil {
+		t.Fatalf("NewGenerator failed: %v", err)
+	}
+
+	users := make(map[string]bool)
+	for i := 0; i < 50; i++ {
+		event, err := gen.GenerateEvent()
+		if err != nil {
+			t.Fatalf("GenerateEvent failed: %v", err)
+		}
+
+		var user, uid, home, shell string
+		if _, err := fmt.Sscanf(event.Line, "user=%s uid=%s home=%s shell=%s", &user, &uid, &home, &shell); err != nil {
+			t.Fatalf("Unexpected line %q: %v", event.Line, err)
+		}
+		if home != "/home/"+user || !strings.HasPrefix(shell, user+"@") {
+			t.Errorf("Variable rendered differently within a line: %q", event.Line)
+		}
+		if event.Values["user"] != user || event.Values["username"] != user {
+			t.Errorf("Expected variable and custom type values %q to be recorded, got %v", user, event.Values)
+		}
+		users[user] = true
+	}
+
+	// Variables are rendered again for every line
+	if len(users) < 2 {
+		t.Errorf("Expected different users across lines, got %v", users)
+	}
+}
+
+func TestTemplateVariableAssignment(t *testing.T) {
+	cfg := &config.Config{
+		Templates: []config.LogTemplate{
+			{
+				Template: `{{$u := username}}user={{$u}} home=/home/{{$u}}`,
+				Weight:   1,
+			},
+		},
+		CustomTypes: map[string][]string{
+			"username": {"alice", "bob", "carol", "dave"},
+		},
+		Outputs: []config.OutputConfig{
+			{
+				Type:    config.OutputTypeStdout,
+				Workers: 1,
+			},
+		},
+	}
+
+	gen, err := NewGenerator(cfg, 0)
+	if err != nil {
+		t.Fatalf("NewGenerator failed: %v", err)
+	}
+
+	for i := 0; i < 20; i++ {
+		line, err := gen.GenerateLogLine()
+		if err != nil {
+			t.Fatalf("GenerateLogLine failed: %v", err)
+		}
+		var user, home string
+		if _, err := fmt.Sscanf(line, "user=%s home=%s", &user, &home); err != nil {
+			t.Fatalf("Unexpected line %q: %v", line, err)
+		}
+		if home != "/home/"+user {
+			t.Errorf("Template variable rendered differently within a line: %q", line)
+		}
+	}
+}
+
+func TestTemplateFields(t *testing.T) {
+	cfg := &config.Config{
+		Templates: []config.LogTemplate{
+			{
+				Fields: config.TemplateFields{
+					{Name: "level", Template: "{{level}}"},
+					{Name: "msg", Template: "{{message}}"},
+					{Name: "user", Template: "{{Username}}"},
+				},
+				Weight: 1,
+			},
+		},
+		CustomTypes: map[string][]string{
+			"level":   {"INFO", "ERROR"},
+			"message": {`user said "hello"`, `path C:	emp`, "multi\nline"},
+		},
+		Outputs: []config.OutputConfig{
+			{
+				Type:    config.OutputTypeStdout,
+				Workers: 1,
+			},
+		},
+		Seed: 12345,
+	}
+
+	gen, err := NewGenerator(cfg, 0)
+	if err != nil {
+		t.Fatalf("NewGenerator failed: %v", err)
+	}
+
+	for i := 0; i < 20; i++ {
+		event, err := gen.GenerateEvent()
+		if err != nil {
+			t.Fatalf("GenerateEvent failed: %v", err)
+		}
+
+		// Values with quotes and backslashes still produce valid JSON
+		var decoded map[string]string
+		if err := json.Unmarshal([]byte(event.Line), &decoded); err != nil {
+			t.Fatalf("Line %q is not valid JSON: %v", event.Line, err)
+		}
+		if !strings.HasPrefix(event.Line, `{"level":`) {
+			t.Errorf("Fields out of order in %q", event.Line)
+		}
+		if len(event.Fields) != 3 {
+			t.Fatalf("Got %d fields, want 3", len(event.Fields))
+		}
+		for _, field := range event.Fields {
+			if decoded[field.Name] != field.Value || event.Values[field.Name] != field.Value {
+				t.Errorf("Field %s = %q, line has %q and values %q", field.Name, field.Value, decoded[field.Name], event.Values[field.Name])
+			}
+		}
+	}
+}
+
 func TestSelectWeightedTemplate(t *testing.T) {
 	// Create a temporary directory for test files
 	tmpDir, err := os.MkdirTemp("", "generator-test-*")
@@ -137,7 +349,7 @@ func TestSelectWeightedTemplate(t *testing.T) {
 	// Test template selection multiple times
 	selectedTemplates := make(map[int]int)
 	for i := 0; i < 1000; i++ {
-		idx := gen.selectWeightedTemplate()
+		idx := gen.selectWeightedTemplate(gen.faker)
 		selectedTemplates[idx]++
 	}
 
@@ -149,6 +361,147 @@ func TestSelectWeightedTemplate(t *testing.T) {
 	}
 }
 
+func TestWeightedCustomType(t *testing.T) {
+	gen := &Generator{}
+	next := gen.createRandomValueFunc(gofakeit.New(12345), []config.CustomValue{
+		{Value: "INFO", Weight: 9},
+		{Value: "ERROR", Weight: 1},
+		{Value: "DEBUG", Weight: 0},
+	})
+
+	counts := make(map[string]int)
+	for i := 0; i < 1000; i++ {
+		counts[next()]++
+	}
+
+	if counts["DEBUG"] != 0 {
+		t.Errorf("Value with weight 0 selected %d times", counts["DEBUG"])
+	}
+	ratio := float64(counts["INFO"]) / float64(counts["ERROR"])
+	if ratio < 6 || ratio > 13 {
+		t.Errorf("Custom type selection ratio outside expected range: %f", ratio)
+	}
+}
+
+func TestIndependentGenerators(t *testing.T) {
+	cfg := &config.Config{
+		Templates: []config.LogTemplate{
+			{
+				Template: "{{level}} {{Number 1 1000000}}",
+				Weight:   1,
+			},
+		},
+		CustomTypes: map[string][]string{
+			"level": {"INFO", "WARN", "ERROR"},
+		},
+		Outputs: []config.OutputConfig{
+			{
+				Type:    config.OutputTypeStdout,
+				Workers: 1,
+			},
+		},
+		Seed: 12345,
+	}
+
+	first, err := NewGenerator(cfg, 0)
+	if err != nil {
+		t.Fatalf("NewGenerator failed: %v", err)
+	}
+	second, err := NewGenerator(cfg, 0)
+	if err != nil {
+		t.Fatalf("NewGenerator failed: %v", err)
+	}
+
+	// Interleaving the generators doesn't change the lines of either
+	for i := 0; i < 10; i++ {
+		a, err := first.GenerateLogLine()
+		if err != nil {
+			t.Fatalf("GenerateLogLine failed: %v", err)
+		}
+		b, err := second.GenerateLogLine()
+		if err != nil {
+			t.Fatalf("GenerateLogLine failed: %v", err)
+		}
+		if a != b {
+			t.Errorf("Line %d differs between generators with the same seed: %q and %q", i, a, b)
+		}
+	}
+}
+
+func TestSeededWorkersReproducible(t *testing.T) {
+	run := func(dir string) {
+		cfg := &config.Config{
+			Templates: []config.LogTemplate{
+				{
+					Template: `{{FormattedDate "2006-01-02T15:04:05.000000000Z07:00"}} {{level}} {{IPv4Address}} {{latency}}`,
+					Weight:   3,
+				},
+				{
+					Template: `{{FormattedDate "2006-01-02T15:04:05Z07:00"}} {{level}} {{Username}}`,
+					Weight:   1,
+				},
+			},
+			CustomTypes: map[string][]string{
+				"level": {"INFO", "WARN", "ERROR"},
+			},
+			WeightedCustomTypes: map[string]config.CustomType{
+				"latency": {Distribution: &config.Distribution{Type: config.DistributionExponential, Rate: 0.01}},
+			},
+			Outputs: []config.OutputConfig{
+				{
+					Type:      config.OutputTypeFile,
+					Workers:   3,
+					BatchSize: 7,
+					Config: map[string]interface{}{
+						"filename": filepath.Join(dir, "test.log"),
+					},
+				},
+			},
+			// The default random clock, ending at the time of the run
+			Seed: 12345,
+		}
+
+		gen, err := NewGenerator(cfg, 300)
+		if err != nil {
+			t.Fatalf("NewGenerator failed: %v", err)
+		}
+		gen.Start()
+		<-gen.Done()
+		if err := gen.Stop(); err != nil {
+			t.Fatalf("Stop failed: %v", err)
+		}
+	}
+
+	first, err := os.MkdirTemp("", "generator-test-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(first)
+	second, err := os.MkdirTemp("", "generator-test-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(second)
+
+	run(first)
+	run(second)
+
+	for i := 0; i < 3; i++ {
+		name := fmt.Sprintf("test_worker%d.log", i)
+		a, err := os.ReadFile(filepath.Join(first, name))
+		if err != nil {
+			t.Fatalf("Failed to read worker file: %v", err)
+		}
+		b, err := os.ReadFile(filepath.Join(second, name))
+		if err != nil {
+			t.Fatalf("Failed to read worker file: %v", err)
+		}
+		if len(a) == 0 || !bytes.Equal(a, b) {
+			t.Errorf("Worker %d output differs between seeded runs", i)
+		}
+	}
+}
+
 func TestStartAndStop(t *testing.T) {
 	// Create a temporary directory for test files
 	tmpDir, err := os.MkdirTemp("", "generator-test-*")
@@ -197,6 +550,188 @@ func TestStartAndStop(t *testing.T) {
 	}
 }
 
+func TestDuration(t *testing.T) {
+	// Create a temporary directory for test files
+	tmpDir, err := os.MkdirTemp("", "generator-test-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	cfg := &config.Config{
+		Templates: []config.LogTemplate{
+			{
+				Template: "test template",
+				Weight:   1,
+			},
+		},
+		Outputs: []config.OutputConfig{
+			{
+				Type:    config.OutputTypeFile,
+				Workers: 2,
+				Config: map[string]interface{}{
+					"filename": filepath.Join(tmpDir, "test.log"),
+				},
+			},
+		},
+		Duration: 200 * time.Millisecond,
+	}
+
+	// Without a count the duration ends the run
+	gen, err := NewGenerator(cfg, 0)
+	if err != nil {
+		t.Fatalf("NewGenerator failed: %v", err)
+	}
+
+	start := time.Now()
+	gen.Start()
+	select {
+	case <-gen.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("Generator did not complete after its duration")
+	}
+	if elapsed := time.Since(start); elapsed < cfg.Duration {
+		t.Errorf("Generator completed after %v, before its duration of %v", elapsed, cfg.Duration)
+	}
+
+	// Stopping after the duration has passed must not panic
+	if err := gen.Stop(); err != nil {
+		t.Fatalf("Stop failed: %v", err)
+	}
+}
+
+func TestStopClosesDone(t *testing.T) {
+	cfg := &config.Config{
+		Templates: []config.LogTemplate{
+			{
+				Template: "test template",
+				Weight:   1,
+			},
+		},
+		Outputs: []config.OutputConfig{
+			{
+				Type:    config.OutputTypeStderr,
+				Workers: 1,
+				Rate:    10,
+			},
+		},
+	}
+
+	gen, err := NewGenerator(cfg, 0)
+	if err != nil {
+		t.Fatalf("NewGenerator failed: %v", err)
+	}
+	gen.Start()
+	if err := gen.Stop(); err != nil {
+		t.Fatalf("Stop failed: %v", err)
+	}
+
+	select {
+	case <-gen.Done():
+	case <-time.After(time.Second):
+		t.Fatal("Done was not closed after Stop")
+	}
+}
+
+func TestStopReturnsRenderError(t *testing.T) {
+	cfg := &config.Config{
+		Templates: []config.LogTemplate{
+			{
+				Template: `{{var "user"}} logged in`,
+				Weight:   1,
+			},
+		},
+		Outputs: []config.OutputConfig{
+			{
+				Type:    config.OutputTypeFile,
+				Workers: 2,
+				Config: map[string]interface{}{
+					"filename": filepath.Join(t.TempDir(), "test.log"),
+				},
+			},
+		},
+	}
+
+	gen, err := NewGenerator(cfg, 0)
+	if err != nil {
+		t.Fatalf("NewGenerator failed: %v", err)
+	}
+	gen.Start()
+
+	// Generation ends by itself instead of failing on every line
+	select {
+	case <-gen.Done():
+	case <-time.After(2 * time.Second):
+		t.Fatal("Done was not closed after a template failed to render")
+	}
+
+	err = gen.Stop()
+	var tplErr *TemplateError
+	if !errors.As(err, &tplErr) {
+		t.Fatalf("Stop() = %v, want a TemplateError", err)
+	}
+	if !strings.Contains(err.Error(), `unknown variable "user"`) {
+		t.Errorf("Stop() = %v, want it to mention the unknown variable", err)
+	}
+}
+
+func TestStopUnreachableTCP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.Config{
+		Templates: []config.LogTemplate{
+			{
+				Template: "test template",
+				Weight:   1,
+			},
+		},
+		Outputs: []config.OutputConfig{
+			{
+				Type:      config.OutputTypeTCP,
+				Workers:   1,
+				BatchSize: 1,
+				Config: map[string]interface{}{
+					"address":         ln.Addr().String(),
+					"reconnect_delay": "10ms",
+				},
+			},
+		},
+	}
+
+	gen, err := NewGenerator(cfg, 0)
+	if err != nil {
+		t.Fatalf("NewGenerator failed: %v", err)
+	}
+
+	// The collector goes away, so the worker keeps reconnecting, as
+	// max_retries defaults to retrying forever
+	conn, err := ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.Close()
+	ln.Close()
+
+	gen.Start()
+	time.Sleep(100 * time.Millisecond)
+
+	stopped := make(chan error, 1)
+	go func() {
+		stopped <- gen.Stop()
+	}()
+	select {
+	case err := <-stopped:
+		if err != nil {
+			t.Errorf("Stop failed: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Stop did not return while the TCP output was reconnecting")
+	}
+}
+
 func TestCreateFuncMap(t *testing.T) {
 	// Create a temporary directory for test files
 	tmpDir, err := os.MkdirTemp("", "generator-test-*")
@@ -232,7 +767,7 @@ func TestCreateFuncMap(t *testing.T) {
 	}
 
 	// Test custom type function
-	if fn, ok := gen.funcMap["test_type"].(func() string); ok {
+	if fn, ok := gen.source.funcMap["test_type"].(func() string); ok {
 		value := fn()
 		if value != "value1" && value != "value2" {
 			t.Errorf("Unexpected value from custom type function: %s", value)
@@ -242,7 +777,7 @@ func TestCreateFuncMap(t *testing.T) {
 	}
 
 	// Test FormattedDate function
-	if fn, ok := gen.funcMap["FormattedDate"].(func(string) string); ok {
+	if fn, ok := gen.source.funcMap["FormattedDate"].(func(string) string); ok {
 		date := fn("2006-01-02")
 		if date == "" {
 			t.Error("FormattedDate returned empty string")
@@ -360,3 +895,123 @@ func BenchmarkGeneratorFileOutput(b *testing.B) {
 
 	time.Sleep(100 * time.Millisecond)
 }
+
+// benchmarkConfig returns the configuration of the basic example for benchmarks
+func benchmarkConfig() *config.Config {
+	return &config.Config{
+		Seed: 12345,
+		Templates: []config.LogTemplate{
+			{
+				Template: "{{FormattedDate \"2006-01-02T15:04:05.000Z07:00\"}} [{{level}}] {{username}} - {{message}}",
+				Weight:   5,
+			},
+			{
+				Template: "{{FormattedDate \"Jan 2 15:04:05\"}} {{level}} [{{service}}] {{IPv4Address}} {{username}}: {{message}}",
+				Weight:   3,
+			},
+			{
+				Fields: config.TemplateFields{
+					{Name: "timestamp", Template: "{{FormattedDate \"2006-01-02T15:04:05.000Z07:00\"}}"},
+					{Name: "level", Template: "{{level}}"},
+					{Name: "service", Template: "{{service}}"},
+					{Name: "message", Template: "{{message}}"},
+					{Name: "user", Template: "{{username}}"},
+					{Name: "ip", Template: "{{IPv4Address}}"},
+				},
+				Weight: 2,
+			},
+		},
+		Outputs: []config.OutputConfig{
+			{
+				Type:    config.OutputTypeStdout,
+				Workers: 1,
+			},
+		},
+		CustomTypes: map[string][]string{
+			"level":    {"INFO", "WARNING", "ERROR", "DEBUG", "TRACE"},
+			"service":  {"API", "AUTH", "DATABASE", "CACHE", "FRONTEND"},
+			"username": {"admin", "system", "app", "service_account", "anonymous"},
+			"message": {
+				"User authenticated successfully",
+				"Failed login attempt - invalid credentials",
+				"Permission denied to resource",
+				"Database connection timeout",
+			},
+		},
+	}
+}
+
+// BenchmarkGenerateLogLine measures rendering lines from the parsed templates
+func BenchmarkGenerateLogLine(b *testing.B) {
+	gen, err := NewGenerator(benchmarkConfig(), 0)
+	if err != nil {
+		b.Fatalf("Failed to create generator: %v", err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := gen.GenerateLogLine(); err != nil {
+			b.Fatalf("GenerateLogLine failed: %v", err)
+		}
+	}
+}
+
+// BenchmarkGenerateLogLineParsed measures rendering lines by parsing the
+// template for every line with gofakeit.Template, for comparison with
+// BenchmarkGenerateLogLine
+func BenchmarkGenerateLogLineParsed(b *testing.B) {
+	cfg := benchmarkConfig()
+	gen, err := NewGenerator(cfg, 0)
+	if err != nil {
+		b.Fatalf("Failed to create generator: %v", err)
+	}
+	faker := gen.source.faker
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		// Only the first two templates are defined as a string
+		tpl := cfg.Templates[i%2]
+		if _, err := faker.Template(tpl.Template, &gofakeit.TemplateOptions{Funcs: gen.source.funcMap}); err != nil {
+			b.Fatalf("Template failed: %v", err)
+		}
+	}
+}
+
+// BenchmarkGenerateLogLineParallel measures rendering lines with a worker
+// source per goroutine, like the workers of an output
+func BenchmarkGenerateLogLineParallel(b *testing.B) {
+	gen, err := NewGenerator(benchmarkConfig(), 0)
+	if err != nil {
+		b.Fatalf("Failed to create generator: %v", err)
+	}
+
+	// Every goroutine gets its own faker and clock, the way initializeOutputs
+	// sets up workers, so nothing is shared between goroutines
+	var mu sync.Mutex
+	parts := runtime.GOMAXPROCS(0)
+	part := 0
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		mu.Lock()
+		faker := gofakeit.New(gen.faker.Uint64())
+		clock := gen.clock.split(part%parts, parts, 0, faker)
+		part++
+		mu.Unlock()
+
+		source, err := gen.newWorkerSource(faker, clock)
+		if err != nil {
+			b.Errorf("newWorkerSource failed: %v", err)
+			return
+		}
+		for pb.Next() {
+			if _, err := source.GenerateLogLine(); err != nil {
+				b.Errorf("GenerateLogLine failed: %v", err)
+				return
+			}
+		}
+	})
+}
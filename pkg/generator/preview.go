@@ -0,0 +1,71 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/P1llus/genlog/pkg/config"
+)
+
+// PreviewLine is a line rendered by Preview, with the template that produced it
+type PreviewLine struct {
+	// Template is the index of the template
+	Template int
+
+	// Name is the name of the template, if it has one
+	Name string
+
+	// Line is the rendered line
+	Line string
+}
+
+// Preview renders count lines without initializing the outputs of cfg, which
+// don't need to be configured. With a template index of 0 or more, every line
+// is rendered from that template, using the variables of the first scenario
+// using it. Otherwise lines are selected like the workers select them,
+// including scenarios.
+func Preview(cfg *config.Config, count, templateIdx int) ([]PreviewLine, error) {
+	if count < 0 {
+		return nil, fmt.Errorf("count must not be negative, got %d", count)
+	}
+	if templateIdx >= len(cfg.Templates) {
+		return nil, fmt.Errorf("template %d does not exist, the config has %d templates", templateIdx, len(cfg.Templates))
+	}
+
+	// A backfill clock spreads the preview lines over its window
+	r, err := NewRenderer(cfg, max(1, count))
+	if err != nil {
+		return nil, err
+	}
+	source := r.source
+
+	var vars map[string]string
+	if templateIdx >= 0 {
+		sampleVars, errs := r.sampleVars(source)
+		if len(errs) > 0 {
+			return nil, errors.Join(errs...)
+		}
+		vars = sampleVars[templateIdx]
+	}
+
+	lines := make([]PreviewLine, 0, count)
+	for len(lines) < count {
+		idx := templateIdx
+		var line string
+		if idx >= 0 {
+			event, err := r.render(source, idx, source.clock.next(), vars)
+			if err != nil {
+				return nil, err
+			}
+			line = event.Line
+		} else {
+			event, next, err := r.nextEvent(source)
+			if err != nil {
+				return nil, err
+			}
+			idx, line = next, event.Line
+		}
+		lines = append(lines, PreviewLine{Template: idx, Name: cfg.Templates[idx].Name, Line: line})
+	}
+	return lines, nil
+}
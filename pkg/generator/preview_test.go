@@ -0,0 +1,105 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/P1llus/genlog/pkg/config"
+)
+
+// previewConfig returns a configuration with a file output, to check that
+// previews don't create it
+func previewConfig(filename string) *config.Config {
+	return &config.Config{
+		Templates: []config.LogTemplate{
+			{Template: "noise {{Word}}", Weight: 5},
+			{Name: "failed", Template: `failed {{var "ip"}}`},
+		},
+		Scenarios: []config.Scenario{
+			{
+				Name:   "brute_force",
+				Weight: 1,
+				Vars:   map[string]string{"ip": "{{IPv4Address}}"},
+				Steps:  []config.ScenarioStep{{Template: "failed", Repeat: 2}},
+			},
+		},
+		Outputs: []config.OutputConfig{
+			{
+				Type:    config.OutputTypeFile,
+				Workers: 1,
+				Config: map[string]interface{}{
+					"filename": filename,
+				},
+			},
+		},
+		Seed: 12345,
+	}
+}
+
+func TestPreview(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "test.log")
+
+	lines, err := Preview(previewConfig(filename), 50, -1)
+	if err != nil {
+		t.Fatalf("Preview failed: %v", err)
+	}
+	if len(lines) != 50 {
+		t.Fatalf("Got %d lines, want 50", len(lines))
+	}
+	seen := make(map[int]bool)
+	for _, line := range lines {
+		seen[line.Template] = true
+		want := "noise "
+		if line.Template == 1 {
+			want = "failed "
+			if line.Name != "failed" {
+				t.Errorf("Name of template 1 = %q, want failed", line.Name)
+			}
+		}
+		if !strings.HasPrefix(line.Line, want) {
+			t.Errorf("Line %q of template %d does not start with %q", line.Line, line.Template, want)
+		}
+	}
+	if !seen[0] || !seen[1] {
+		t.Errorf("Expected lines of both templates, got templates %v", seen)
+	}
+
+	// The same seed previews the same lines
+	again, err := Preview(previewConfig(filename), 50, -1)
+	if err != nil {
+		t.Fatalf("Preview failed: %v", err)
+	}
+	for i := range lines {
+		if lines[i] != again[i] {
+			t.Fatalf("Line %d differs between previews with the same seed: %q and %q", i, lines[i].Line, again[i].Line)
+		}
+	}
+
+	if _, err := os.Stat(filename); !os.IsNotExist(err) {
+		t.Error("Preview created the output file")
+	}
+}
+
+func TestPreviewTemplate(t *testing.T) {
+	cfg := previewConfig(filepath.Join(t.TempDir(), "test.log"))
+
+	// Templates of scenarios get the scenario variables
+	lines, err := Preview(cfg, 5, 1)
+	if err != nil {
+		t.Fatalf("Preview failed: %v", err)
+	}
+	for _, line := range lines {
+		if line.Template != 1 || !strings.HasPrefix(line.Line, "failed ") || line.Line == "failed " {
+			t.Errorf("Unexpected line %+v", line)
+		}
+	}
+
+	if _, err := Preview(cfg, 5, 2); err == nil {
+		t.Error("Expected error for a template index out of range, got nil")
+	}
+	if _, err := Preview(cfg, -1, -1); err == nil {
+		t.Error("Expected error for a negative count, got nil")
+	}
+}
@@ -0,0 +1,470 @@
+package generator
+
+import (
+	"fmt"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/P1llus/genlog/pkg/config"
+	"github.com/P1llus/genlog/pkg/output"
+	"github.com/brianvoe/gofakeit/v7"
+)
+
+// Renderer renders log lines from the templates of a configuration. It holds
+// the parsed templates, the custom types and functions available to them and
+// the random source, but no outputs, so it can be used to generate lines in
+// memory. Generator runs a renderer with outputs and workers.
+type Renderer struct {
+	config           *config.Config
+	faker            *gofakeit.Faker // random source of the renderer, seeding the worker ones
+	clock            *clock
+	source           *workerSource            // renders the lines of GenerateLogLine and GenerateEvent
+	location         *time.Location           // global time zone
+	locations        []*time.Location         // time zone of every template
+	templateIndex    map[string]int           // index of every named template
+	varNames         [][]string               // sorted variable names of every template
+	scenarioVarNames [][]string               // sorted variable names of every scenario
+	templates        *templates               // every template text, parsed once
+	distributions    map[string]*distribution // distribution of every custom type with one, shared by the sources
+	encoder          output.Encoder           // encodes templates defined as fields in GenerateLogLine
+	totalWeight      int
+	opens            bool // templates open sessions with session: start
+}
+
+// NewRenderer creates a renderer for the templates of the given configuration.
+// Outputs are not required and not initialized. count is the number of lines
+// a backfill clock spreads its timestamps over, and may be 0 for other clocks.
+//
+// It parses every template once, so syntax errors in templates are reported
+// here, initializes the function map for template rendering and calculates
+// the total template weight for weighted random selection. The function map
+// includes all custom types from the configuration, making them available as
+// placeholders in templates.
+//
+// Every renderer has its own random source, so renderers don't affect each
+// other, and a configured seed makes its lines reproducible.
+func NewRenderer(cfg *config.Config, count int) (*Renderer, error) {
+	if err := cfg.ValidateTemplates(); err != nil {
+		return nil, fmt.Errorf("error validating config: %w", err)
+	}
+	return newRenderer(cfg, count)
+}
+
+// newRenderer creates a renderer for a validated configuration. count is the
+// number of lines a backfill clock spreads its timestamps over.
+func newRenderer(cfg *config.Config, count int) (*Renderer, error) {
+	// Calculate total weight for template and scenario selection
+	totalWeight := 0
+	opens := false
+	templateIndex := make(map[string]int)
+	for i, tpl := range cfg.Templates {
+		totalWeight += tpl.Weight
+		opens = opens || tpl.Session == config.SessionStart
+		if tpl.Name != "" {
+			templateIndex[tpl.Name] = i
+		}
+	}
+
+	// Sort the variable names of every template and scenario, so variables are
+	// rendered in the same order and seeded runs are reproducible
+	varNames := make([][]string, len(cfg.Templates))
+	for i, tpl := range cfg.Templates {
+		varNames[i] = sortedKeys(tpl.Vars)
+	}
+	scenarioVarNames := make([][]string, len(cfg.Scenarios))
+	for i, scenario := range cfg.Scenarios {
+		totalWeight += scenario.Weight
+		scenarioVarNames[i] = sortedKeys(scenario.Vars)
+	}
+
+	// Templates defined as fields render JSON lines unless an output has another encoder
+	jsonEncoder, err := output.NewEncoder(config.EncoderConfig{Type: config.EncoderJSON})
+	if err != nil {
+		return nil, err
+	}
+
+	// Create the renderer instance
+	// A seed of 0 creates a randomly seeded source
+	r := &Renderer{
+		config:           cfg,
+		faker:            gofakeit.New(cfg.Seed),
+		totalWeight:      totalWeight,
+		templateIndex:    templateIndex,
+		varNames:         varNames,
+		scenarioVarNames: scenarioVarNames,
+		encoder:          jsonEncoder,
+		opens:            opens,
+	}
+
+	// Resolve the time zone of every template, falling back to the global one
+	r.location, err = loadLocation(cfg.Timezone)
+	if err != nil {
+		return nil, err
+	}
+	for _, tpl := range cfg.Templates {
+		loc := r.location
+		if tpl.Timezone != "" {
+			if loc, err = loadLocation(tpl.Timezone); err != nil {
+				return nil, err
+			}
+		}
+		r.locations = append(r.locations, loc)
+	}
+
+	// Build the distributions of custom types once, as a zipf distribution
+	// holds up to a million cumulative probabilities
+	r.distributions = make(map[string]*distribution)
+	for typeName, customType := range cfg.AllCustomTypes() {
+		if customType.Distribution != nil {
+			r.distributions[typeName] = newDistribution(*customType.Distribution, nil)
+		}
+	}
+
+	// Initialize the clock used for timestamps. Realtime and simulated clocks
+	// are shared by every worker, so the clock has its own random source.
+	r.clock, err = newClock(cfg.Clock, count, r.location, gofakeit.New(r.faker.Uint64()))
+	if err != nil {
+		return nil, fmt.Errorf("error creating clock: %w", err)
+	}
+	if cfg.Seed != 0 {
+		r.clock.pinEnd()
+	}
+
+	// Parse every template once. Parsing only needs the names of the functions,
+	// every worker source binds its own functions to the parsed templates.
+	r.templates, err = r.parseTemplates(r.createFuncMap(&workerSource{r: r, faker: r.faker, clock: r.clock}))
+	if err != nil {
+		return nil, err
+	}
+
+	// Initialize the function map and sessions for template rendering
+	if r.source, err = r.newWorkerSource(r.faker, r.clock); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// selectWeightedTemplate selects a random template index based on the weights.
+// Templates with higher weights have a proportionally higher chance of being selected.
+// Scenarios are selected the same way, and are numbered after the templates,
+// so an index of len(Templates) or more selects scenario index-len(Templates).
+func (r *Renderer) selectWeightedTemplate(faker *gofakeit.Faker) int {
+	if r.totalWeight <= 0 || len(r.config.Templates) == 0 {
+		return 0
+	}
+
+	n := faker.IntRange(0, r.totalWeight-1)
+	sum := 0
+	for i, tpl := range r.config.Templates {
+		sum += tpl.Weight
+		if n < sum {
+			return i
+		}
+	}
+	for i, scenario := range r.config.Scenarios {
+		sum += scenario.Weight
+		if n < sum {
+			return len(r.config.Templates) + i
+		}
+	}
+	return 0
+}
+
+// createFuncMap creates a map of functions that can be used in the templates
+// of source. The map includes:
+// 1. The gofakeit functions, drawing their values from the faker of source
+// 2. All custom types from the configuration, each as a function returning a random value
+// 3. Built-in helper functions like FormattedDate and Field
+//
+// Note: The addLookupFunc functionality of gofakeit is not available when rendering
+// inside go templates. This is why we have to create a map of the function names along
+// with the function used to generate the random value.
+//
+// While a line is rendered, custom types, session attributes, variables and
+// Field record the values they produce for outputs. Only the first value of a
+// custom type, session attribute or variable used more than once is recorded.
+// The time helpers use the timestamp of the line, so every timestamp in a line
+// is the same. Session attributes and scenario variables, rendered outside of
+// lines, use the timestamp of the line or scenario that needs them, so they
+// don't advance the clock.
+func (r *Renderer) createFuncMap(source *workerSource) template.FuncMap {
+	funcMap := fakerFuncs(source.faker)
+
+	// record keeps the first value of name for the line being rendered
+	record := func(name, value string) {
+		if l := source.line; l != nil {
+			if _, ok := l.values[name]; !ok {
+				l.values[name] = value
+			}
+		}
+	}
+
+	// Add each custom type as a function that returns a weighted random value from its slice,
+	// or a number from its distribution
+	for typeName, customType := range r.config.AllCustomTypes() {
+		// Create a function to properly capture the values for each custom type
+		next := r.createRandomValueFunc(source.faker, customType.Values)
+		if d, ok := r.distributions[typeName]; ok {
+			next = d.withFaker(source.faker).format
+		}
+		funcMap[typeName] = func() string {
+			value := next()
+			record(typeName, value)
+			return value
+		}
+	}
+
+	// Add built-in helper functions
+	for name, fn := range timeFuncs(func() time.Time {
+		if l := source.line; l != nil {
+			return l.now
+		}
+		return source.now
+	}) {
+		funcMap[name] = fn
+	}
+
+	// Field names a value so outputs can use it, for example as the syslog severity.
+	// It renders the value unchanged.
+	funcMap["Field"] = func(name string, value any) string {
+		str := fmt.Sprint(value)
+		if l := source.line; l != nil {
+			l.values[name] = str
+		}
+		return str
+	}
+
+	// session returns an attribute of the line's session. It is not available
+	// to the session attributes themselves.
+	funcMap["session"] = func(name string) (string, error) {
+		l := source.line
+		if l == nil {
+			return "", fmt.Errorf("session %q is not available here", name)
+		}
+		value, err := l.session.attribute(name)
+		if err != nil {
+			return "", err
+		}
+		record(name, value)
+		return value, nil
+	}
+
+	// var returns a variable of the template or scenario of the line
+	funcMap["var"] = func(name string) (string, error) {
+		l := source.line
+		if l == nil {
+			return "", fmt.Errorf("unknown variable %q", name)
+		}
+		value, ok := l.vars[name]
+		if !ok {
+			return "", fmt.Errorf("unknown variable %q", name)
+		}
+		record(name, value)
+		return value, nil
+	}
+
+	return funcMap
+}
+
+// line is the state of a single line while it is rendered
+type line struct {
+	values  map[string]string // values recorded for outputs
+	now     time.Time         // timestamp of the line
+	session *lineSession      // session of the line
+	vars    map[string]string // variables of the template and scenario of the line, if any
+}
+
+// createRandomValueFunc creates a function that returns a random value from the given slice.
+// Values with higher weights have a proportionally higher chance of being selected.
+// This is used to translate configured custom types to a funcMap for the template functions.
+func (r *Renderer) createRandomValueFunc(faker *gofakeit.Faker, values []config.CustomValue) func() string {
+	totalWeight := 0
+	for _, value := range values {
+		totalWeight += value.Weight
+	}
+
+	return func() string {
+		if len(values) == 0 {
+			return ""
+		}
+		if totalWeight <= 0 {
+			return values[0].Value
+		}
+
+		n := faker.IntRange(0, totalWeight-1)
+		sum := 0
+		for _, value := range values {
+			sum += value.Weight
+			if n < sum {
+				return value.Value
+			}
+		}
+		return values[0].Value
+	}
+}
+
+// GenerateLogLine generates a single log line using a randomly selected template.
+// This is useful for generating log lines programmatically without writing to a file,
+// such as when streaming logs directly to another system.
+func (r *Renderer) GenerateLogLine() (string, error) {
+	event, err := r.GenerateEvent()
+	if err != nil {
+		return "", err
+	}
+	return event.Line, nil
+}
+
+// GenerateEvent generates a single log line like GenerateLogLine, together with
+// the values selected for custom types and Field calls while rendering it.
+func (r *Renderer) GenerateEvent() (output.Event, error) {
+	return r.generateEvent(r.source)
+}
+
+// generateEvent renders the next line with the random source, clock, functions
+// and sessions of source. This is the next line of a started scenario when one
+// is due, otherwise a randomly selected template or the first line of a
+// randomly selected scenario.
+func (r *Renderer) generateEvent(source *workerSource) (output.Event, error) {
+	event, _, err := r.nextEvent(source)
+	return event, err
+}
+
+// nextEvent renders the next line like generateEvent, and also returns the
+// index of the template that produced it
+func (r *Renderer) nextEvent(source *workerSource) (output.Event, int, error) {
+	// First check if we have any templates
+	if len(r.config.Templates) == 0 {
+		return output.Event{}, 0, fmt.Errorf("no templates available")
+	}
+
+	source.renderMu.Lock()
+	defer source.renderMu.Unlock()
+
+	now := source.clock.next()
+	run := source.nextRun(now)
+	if run == nil {
+		templateIdx := r.selectWeightedTemplate(source.faker)
+		if templateIdx < len(r.config.Templates) {
+			event, err := r.render(source, templateIdx, now, nil)
+			return event, templateIdx, err
+		}
+
+		var err error
+		run, err = r.startScenario(templateIdx-len(r.config.Templates), now, source)
+		if err != nil {
+			return output.Event{}, 0, err
+		}
+	}
+
+	templateIdx := run.template()
+	event, err := r.render(source, templateIdx, run.due, run.vars)
+	if run.advance() {
+		source.resume(run)
+	}
+	return event, templateIdx, err
+}
+
+// render renders the template at templateIdx with the timestamp now and the
+// variables of the scenario the line belongs to. The variables of the template
+// are rendered first, and can use the scenario variables.
+func (r *Renderer) render(source *workerSource, templateIdx int, now time.Time, vars map[string]string) (output.Event, error) {
+	selectedTemplate := r.config.Templates[templateIdx]
+
+	l := &line{
+		values:  make(map[string]string),
+		now:     now.In(r.locations[templateIdx]),
+		session: &lineSession{pool: source.sessions, marker: selectedTemplate.Session},
+		vars:    vars,
+	}
+	source.now = l.now
+	defer l.session.done()
+
+	names := r.varNames[templateIdx]
+	if len(names) > 0 {
+		l.vars = make(map[string]string, len(vars)+len(names))
+		for name, value := range vars {
+			l.vars[name] = value
+		}
+	}
+	for i, name := range names {
+		value, err := source.execute(source.templates.vars[templateIdx][i], l)
+		if err != nil {
+			return output.Event{}, fmt.Errorf("error rendering var %s: %w", name, err)
+		}
+		l.vars[name] = value
+	}
+
+	// Templates defined as fields are encoded as JSON, and encoded again by
+	// outputs with another encoder
+	if len(selectedTemplate.Fields) > 0 {
+		fields := make([]output.Field, len(selectedTemplate.Fields))
+		for i, field := range selectedTemplate.Fields {
+			value, err := source.execute(source.templates.fields[templateIdx][i], l)
+			if err != nil {
+				return output.Event{}, fmt.Errorf("error rendering field %s: %w", field.Name, err)
+			}
+			fields[i] = output.Field{Name: field.Name, Value: value}
+			l.values[field.Name] = value
+		}
+		return output.Event{Line: r.encoder.Encode(fields), Values: l.values, Fields: fields, Time: l.now}, nil
+	}
+
+	logLine, err := source.execute(source.templates.lines[templateIdx], l)
+	if err != nil {
+		return output.Event{}, fmt.Errorf("error generating log line: %w", err)
+	}
+
+	return output.Event{Line: logLine, Values: l.values, Time: l.now}, nil
+}
+
+// workerSource generates the events of a single worker. It has its own random
+// source, so workers don't depend on each other's scheduling, its own clock,
+// so backfilled timestamps can be split between workers, and its own sessions
+// and scenarios.
+type workerSource struct {
+	r         *Renderer
+	faker     *gofakeit.Faker
+	clock     *clock
+	funcMap   template.FuncMap
+	templates *templates // parsed templates bound to funcMap
+	sessions  *sessionPool
+	line      *line          // line being rendered, used by the functions in funcMap
+	now       time.Time      // timestamp of the line or scenario being rendered
+	runs      []*scenarioRun // started scenarios with lines left
+	mu        sync.Mutex
+	renderMu  sync.Mutex // renders one line at a time, as line is shared by the functions
+}
+
+// newWorkerSource creates a source drawing random values from faker and
+// timestamps from clock
+func (r *Renderer) newWorkerSource(faker *gofakeit.Faker, clock *clock) (*workerSource, error) {
+	s := &workerSource{
+		r:     r,
+		faker: faker,
+		clock: clock,
+	}
+	s.funcMap = r.createFuncMap(s)
+	templates, err := r.templates.bind(s.funcMap)
+	if err != nil {
+		return nil, err
+	}
+	s.templates = templates
+	s.sessions = newSessionPool(r.config.Sessions, r.opens, s)
+	return s, nil
+}
+
+// GenerateLogLine generates a single log line with the worker's clock
+func (s *workerSource) GenerateLogLine() (string, error) {
+	event, err := s.GenerateEvent()
+	if err != nil {
+		return "", err
+	}
+	return event.Line, nil
+}
+
+// GenerateEvent generates a single event with the worker's clock
+func (s *workerSource) GenerateEvent() (output.Event, error) {
+	return s.r.generateEvent(s)
+}
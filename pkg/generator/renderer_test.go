@@ -0,0 +1,85 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/P1llus/genlog/pkg/config"
+)
+
+func TestNewRenderer(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		cfg     *config.Config
+		count   int
+		want    string
+		wantErr string
+	}{
+		{
+			name: "without outputs",
+			cfg: &config.Config{
+				Templates: []config.LogTemplate{{Template: "{{level}} user {{user}} logged in", Weight: 1}},
+				CustomTypes: map[string][]string{
+					"level": {"INFO"},
+					"user":  {"alice"},
+				},
+			},
+			want: "INFO user alice logged in",
+		},
+		{
+			name: "outputs are not initialized",
+			cfg: &config.Config{
+				Templates: []config.LogTemplate{{Template: "plain line", Weight: 1}},
+				Outputs:   []config.OutputConfig{{Type: config.OutputTypeTCP, Workers: 1}},
+			},
+			want: "plain line",
+		},
+		{
+			name: "backfill clock with a count",
+			cfg: &config.Config{
+				Templates: []config.LogTemplate{{Template: `{{FormattedDate "2006-01-02"}}`, Weight: 1}},
+				Clock:     config.ClockConfig{Mode: config.ClockModeBackfill, Start: start, End: start.Add(time.Hour)},
+			},
+			count: 10,
+			want:  "2024-01-01",
+		},
+		{
+			name: "backfill clock without a count",
+			cfg: &config.Config{
+				Templates: []config.LogTemplate{{Template: "plain line", Weight: 1}},
+				Clock:     config.ClockConfig{Mode: config.ClockModeBackfill, Start: start, End: start.Add(time.Hour)},
+			},
+			wantErr: "backfill clock requires a count",
+		},
+		{
+			name:    "no templates",
+			cfg:     &config.Config{},
+			wantErr: "error validating config",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := NewRenderer(tt.cfg, tt.count)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("NewRenderer() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewRenderer failed: %v", err)
+			}
+			line, err := r.GenerateLogLine()
+			if err != nil {
+				t.Fatalf("GenerateLogLine failed: %v", err)
+			}
+			if line != tt.want {
+				t.Errorf("GenerateLogLine() = %q, want %q", line, tt.want)
+			}
+		})
+	}
+}
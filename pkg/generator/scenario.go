@@ -0,0 +1,94 @@
+package generator
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/P1llus/genlog/pkg/config"
+)
+
+// scenarioRun is a started scenario with the lines it has left
+type scenarioRun struct {
+	scenario *config.Scenario
+	steps    []int             // template index of every step
+	vars     map[string]string // rendered scenario variables
+	step     int               // next step
+	repeat   int               // lines of the next step already rendered
+	due      time.Time         // timestamp of the next line
+}
+
+// startScenario starts scenario at now, rendering its variables with the functions of source
+func (r *Renderer) startScenario(scenarioIdx int, now time.Time, source *workerSource) (*scenarioRun, error) {
+	scenario := &r.config.Scenarios[scenarioIdx]
+	run := &scenarioRun{
+		scenario: scenario,
+		vars:     make(map[string]string, len(scenario.Vars)),
+		due:      now,
+	}
+	for _, step := range scenario.Steps {
+		run.steps = append(run.steps, r.templateIndex[step.Template])
+	}
+	source.now = now.In(r.location)
+
+	// Render the variables in a fixed order, so seeded runs are reproducible
+	for i, name := range r.scenarioVarNames[scenarioIdx] {
+		value, err := source.execute(source.templates.scenarioVars[scenarioIdx][i], nil)
+		if err != nil {
+			return nil, fmt.Errorf("error rendering variable %s of scenario %s: %w", name, scenario.Name, err)
+		}
+		run.vars[name] = value
+	}
+	return run, nil
+}
+
+// template returns the template index of the next line
+func (r *scenarioRun) template() int {
+	return r.steps[r.step]
+}
+
+// advance moves to the next line of the scenario, and reports whether the
+// scenario has lines left
+func (r *scenarioRun) advance() bool {
+	r.repeat++
+	if r.repeat >= max(1, r.scenario.Steps[r.step].Repeat) {
+		r.step++
+		r.repeat = 0
+	}
+	if r.step >= len(r.steps) {
+		return false
+	}
+	r.due = r.due.Add(r.scenario.Steps[r.step].Delay)
+	return true
+}
+
+// nextRun returns the started scenario whose next line is due at now and
+// removes it from the source. Lines of a scenario are due once the clock
+// reaches their timestamp, except with the random clock, where timestamps
+// are not ordered and scenarios continue right away.
+func (s *workerSource) nextRun(now time.Time) *scenarioRun {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	next := -1
+	for i, run := range s.runs {
+		if next < 0 || run.due.Before(s.runs[next].due) {
+			next = i
+		}
+	}
+	if next < 0 {
+		return nil
+	}
+	run := s.runs[next]
+	if s.clock.cfg.Mode != config.ClockModeRandom && run.due.After(now) {
+		return nil
+	}
+	s.runs = append(s.runs[:next], s.runs[next+1:]...)
+	return run
+}
+
+// resume keeps a scenario with lines left for later lines of the source
+func (s *workerSource) resume(run *scenarioRun) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.runs = append(s.runs, run)
+}
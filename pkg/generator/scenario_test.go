@@ -0,0 +1,229 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/P1llus/genlog/pkg/config"
+)
+
+func TestScenarioSteps(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	cfg := &config.Config{
+		Templates: []config.LogTemplate{
+			{Template: `{{FormattedDate "2006-01-02T15:04:05Z07:00"}} noise`, Weight: 20},
+			{Name: "failed", Template: `{{FormattedDate "2006-01-02T15:04:05Z07:00"}} failed {{var "ip"}}`},
+			{Name: "success", Template: `{{FormattedDate "2006-01-02T15:04:05Z07:00"}} success {{var "ip"}}`},
+		},
+		Scenarios: []config.Scenario{
+			{
+				Name:   "brute_force",
+				Weight: 1,
+				Vars:   map[string]string{"ip": "{{IPv4Address}}"},
+				Steps: []config.ScenarioStep{
+					{Template: "failed", Repeat: 5, Delay: 5 * time.Second},
+					{Template: "success", Delay: 2 * time.Second},
+				},
+			},
+		},
+		Outputs: []config.OutputConfig{
+			{
+				Type:    config.OutputTypeStdout,
+				Workers: 1,
+			},
+		},
+		Clock: config.ClockConfig{Mode: config.ClockModeBackfill, Start: start, End: start.Add(time.Hour)},
+		Seed:  12345,
+	}
+
+	gen, err := NewGenerator(cfg, 2000)
+	if err != nil {
+		t.Fatalf("NewGenerator failed: %v", err)
+	}
+
+	type step struct {
+		kind string
+		at   time.Time
+	}
+	runs := make(map[string][]step)
+	var last time.Time
+	for i := 0; i < 2000; i++ {
+		line, err := gen.GenerateLogLine()
+		if err != nil {
+			t.Fatalf("GenerateLogLine failed: %v", err)
+		}
+		fields := strings.Fields(line)
+		at, err := time.Parse(time.RFC3339, fields[0])
+		if err != nil {
+			t.Fatalf("Failed to parse timestamp of %q: %v", line, err)
+		}
+		if at.Before(last) {
+			t.Fatalf("Timestamp of %q before the previous line at %v", line, last)
+		}
+		last = at
+		if len(fields) == 3 {
+			runs[fields[2]] = append(runs[fields[2]], step{fields[1], at})
+		}
+	}
+
+	if len(runs) < 5 {
+		t.Fatalf("Expected several scenarios to run, got %d", len(runs))
+	}
+	complete := 0
+	for ip, steps := range runs {
+		for i, s := range steps {
+			want := "failed"
+			if i == 5 {
+				want = "success"
+			}
+			if s.kind != want || i > 5 {
+				t.Fatalf("Scenario for %s: line %d is %q, want %q", ip, i, s.kind, want)
+			}
+			if i > 0 && s.at.Sub(steps[i-1].at) < 2*time.Second {
+				t.Errorf("Scenario for %s: line %d only %v after the previous line", ip, i, s.at.Sub(steps[i-1].at))
+			}
+		}
+		if len(steps) == 6 {
+			complete++
+			if d := steps[5].at.Sub(steps[0].at); d > 60*time.Second {
+				t.Errorf("Scenario for %s took %v, want at most 60s", ip, d)
+			}
+		}
+	}
+	if complete == 0 {
+		t.Error("No scenario completed")
+	}
+}
+
+func TestScenarioRandomClockContinues(t *testing.T) {
+	cfg := &config.Config{
+		Templates: []config.LogTemplate{
+			{Template: "noise", Weight: 1},
+			{Name: "a", Template: `a {{var "id"}}`},
+			{Name: "b", Template: `b {{var "id"}}`},
+		},
+		Scenarios: []config.Scenario{
+			{
+				Weight: 1,
+				Vars:   map[string]string{"id": "{{UUID}}"},
+				Steps: []config.ScenarioStep{
+					{Template: "a", Repeat: 2, Delay: time.Minute},
+					{Template: "b", Delay: time.Hour},
+				},
+			},
+		},
+		Outputs: []config.OutputConfig{
+			{
+				Type:    config.OutputTypeStdout,
+				Workers: 1,
+			},
+		},
+		Seed: 12345,
+	}
+
+	gen, err := NewGenerator(cfg, 0)
+	if err != nil {
+		t.Fatalf("NewGenerator failed: %v", err)
+	}
+
+	// With random timestamps, the lines of a scenario follow each other directly
+	var scenario []string
+	for i := 0; i < 200; i++ {
+		line, err := gen.GenerateLogLine()
+		if err != nil {
+			t.Fatalf("GenerateLogLine failed: %v", err)
+		}
+		if line == "noise" {
+			if len(scenario) != 0 {
+				t.Fatalf("Noise in the middle of scenario lines %v", scenario)
+			}
+			continue
+		}
+		scenario = append(scenario, line)
+		if len(scenario) == 3 {
+			id := strings.TrimPrefix(scenario[0], "a ")
+			if scenario[1] != "a "+id || scenario[2] != "b "+id {
+				t.Fatalf("Unexpected scenario lines %v", scenario)
+			}
+			scenario = nil
+		}
+	}
+}
+
+func TestVarOutsideScenario(t *testing.T) {
+	cfg := &config.Config{
+		Templates: []config.LogTemplate{
+			{Template: `{{var "ip"}}`, Weight: 1},
+		},
+		Outputs: []config.OutputConfig{
+			{
+				Type:    config.OutputTypeStdout,
+				Workers: 1,
+			},
+		},
+	}
+
+	gen, err := NewGenerator(cfg, 0)
+	if err != nil {
+		t.Fatalf("NewGenerator failed: %v", err)
+	}
+	if _, err := gen.GenerateLogLine(); err == nil {
+		t.Error("Expected error for var outside of a scenario, got nil")
+	}
+}
+
+func TestTimeOutsideLines(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	const layout = "2006-01-02T15:04:05.000000Z07:00"
+	cfg := &config.Config{
+		Templates: []config.LogTemplate{
+			{Name: "login", Template: `{{var "started"}} {{session "opened"}} {{FormattedDate "` + layout + `"}}`},
+		},
+		Scenarios: []config.Scenario{
+			{
+				Name:   "login",
+				Weight: 1,
+				Vars:   map[string]string{"started": `{{FormattedDate "` + layout + `"}}`},
+				Steps:  []config.ScenarioStep{{Template: "login"}},
+			},
+		},
+		Sessions: config.SessionConfig{
+			Attributes: map[string]string{"opened": `{{FormattedDate "` + layout + `"}}`},
+		},
+		Timezone: "+05:00",
+		Clock:    config.ClockConfig{Mode: config.ClockModeBackfill, Start: start, End: start.Add(time.Hour)},
+		Seed:     12345,
+	}
+
+	const count = 10
+	r, err := NewRenderer(cfg, count)
+	if err != nil {
+		t.Fatalf("NewRenderer failed: %v", err)
+	}
+
+	// Scenario variables and session attributes take the time of the line that
+	// needs them, in the configured time zone, instead of advancing the clock
+	var last time.Time
+	for i := 0; i < count; i++ {
+		line, err := r.GenerateLogLine()
+		if err != nil {
+			t.Fatalf("GenerateLogLine failed: %v", err)
+		}
+		fields := strings.Fields(line)
+		if i == 0 && (fields[0] != fields[2] || fields[1] != fields[2]) {
+			t.Errorf("First line %q has different timestamps", line)
+		}
+		if !strings.HasSuffix(fields[2], "+05:00") {
+			t.Errorf("Timestamp %s is not in the configured time zone", fields[2])
+		}
+		at, err := time.Parse(layout, fields[2])
+		if err != nil {
+			t.Fatalf("Failed to parse timestamp of %q: %v", line, err)
+		}
+		if wantMin := start.Add(time.Duration(i) * time.Hour / count); at.Before(wantMin) || at.Before(last) {
+			t.Errorf("Line %d at %v, want at least %v and in order", i, at, wantMin)
+		}
+		last = at
+	}
+}
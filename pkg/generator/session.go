@@ -0,0 +1,135 @@
+package generator
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/P1llus/genlog/pkg/config"
+)
+
+// session is a live entity whose attributes stay the same across lines
+type session struct {
+	attributes map[string]string
+	remaining  int // lines left before the session ends
+}
+
+// sessionPool keeps the live sessions of a worker
+type sessionPool struct {
+	concurrency int
+	lifetime    int
+	names       []string // attribute names, sorted so seeded runs render them in the same order
+	opens       bool     // sessions are opened by templates with session: start
+	source      *workerSource
+	live        []*session
+	mu          sync.Mutex
+}
+
+// newSessionPool creates the session pool of a worker. When opens is set,
+// templates with session: start open the sessions, otherwise sessions are
+// opened as needed to keep the configured number of sessions live.
+func newSessionPool(cfg config.SessionConfig, opens bool, source *workerSource) *sessionPool {
+	p := &sessionPool{
+		concurrency: cfg.Concurrency,
+		lifetime:    cfg.Lifetime,
+		names:       sortedKeys(sessionAttributes(cfg)),
+		opens:       opens,
+		source:      source,
+	}
+	if p.concurrency == 0 {
+		p.concurrency = config.DefaultSessionConcurrency
+	}
+	if p.lifetime == 0 {
+		p.lifetime = config.DefaultSessionLifetime
+	}
+	return p
+}
+
+// sessionAttributes returns the configured attribute templates, or the
+// default ones when none are configured
+func sessionAttributes(cfg config.SessionConfig) map[string]string {
+	if len(cfg.Attributes) == 0 {
+		return config.DefaultSessionAttributes
+	}
+	return cfg.Attributes
+}
+
+// acquire returns the session for a line with the given marker
+func (p *sessionPool) acquire(marker config.SessionMarker) (*session, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if marker == config.SessionStart || len(p.live) == 0 || (!p.opens && len(p.live) < p.concurrency) {
+		return p.open()
+	}
+	return p.live[p.source.faker.IntRange(0, len(p.live)-1)], nil
+}
+
+// open starts a new session. When the pool is full, the oldest session ends
+// to make room.
+func (p *sessionPool) open() (*session, error) {
+	s := &session{
+		attributes: make(map[string]string, len(p.names)),
+		remaining:  p.source.faker.IntRange(max(1, p.lifetime/2), max(1, p.lifetime*3/2)),
+	}
+	for _, name := range p.names {
+		value, err := p.source.execute(p.source.templates.attributes[name], nil)
+		if err != nil {
+			return nil, fmt.Errorf("error rendering session attribute %s: %w", name, err)
+		}
+		s.attributes[name] = value
+	}
+
+	if len(p.live) >= p.concurrency {
+		p.live = slices.Delete(p.live, 0, 1)
+	}
+	p.live = append(p.live, s)
+	return s, nil
+}
+
+// release counts a line of the session, and ends the session after its last
+// line or after a line with session: end
+func (p *sessionPool) release(s *session, marker config.SessionMarker) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	s.remaining--
+	if marker != config.SessionEnd && s.remaining > 0 {
+		return
+	}
+	if i := slices.Index(p.live, s); i >= 0 {
+		p.live = slices.Delete(p.live, i, i+1)
+	}
+}
+
+// lineSession acquires the session of a single line when the template first
+// uses it, so every session attribute in a line comes from the same session
+type lineSession struct {
+	pool    *sessionPool
+	marker  config.SessionMarker
+	current *session
+}
+
+// attribute returns an attribute of the line's session
+func (l *lineSession) attribute(name string) (string, error) {
+	if l.current == nil {
+		s, err := l.pool.acquire(l.marker)
+		if err != nil {
+			return "", err
+		}
+		l.current = s
+	}
+
+	value, ok := l.current.attributes[name]
+	if !ok {
+		return "", fmt.Errorf("unknown session attribute %q", name)
+	}
+	return value, nil
+}
+
+// done releases the session after the line is rendered, if the line used one
+func (l *lineSession) done() {
+	if l.current != nil {
+		l.pool.release(l.current, l.marker)
+	}
+}
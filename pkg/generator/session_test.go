@@ -0,0 +1,137 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/P1llus/genlog/pkg/config"
+)
+
+// newSessionGenerator creates a seeded generator writing to stdout
+func newSessionGenerator(t *testing.T, templates []config.LogTemplate, sessions config.SessionConfig) *Generator {
+	t.Helper()
+	cfg := &config.Config{
+		Templates: templates,
+		Outputs: []config.OutputConfig{
+			{
+				Type:    config.OutputTypeStdout,
+				Workers: 1,
+			},
+		},
+		Sessions: sessions,
+		Seed:     12345,
+	}
+	gen, err := NewGenerator(cfg, 0)
+	if err != nil {
+		t.Fatalf("NewGenerator failed: %v", err)
+	}
+	return gen
+}
+
+func TestSessionsCorrelateAcrossLines(t *testing.T) {
+	gen := newSessionGenerator(t, []config.LogTemplate{
+		{Template: `{{session "session_id"}} {{session "user"}} {{session "src_ip"}} {{session "user"}}`, Weight: 1},
+	}, config.SessionConfig{Concurrency: 2, Lifetime: 1000})
+
+	users := make(map[string]string)
+	for i := 0; i < 50; i++ {
+		event, err := gen.GenerateEvent()
+		if err != nil {
+			t.Fatalf("GenerateEvent failed: %v", err)
+		}
+		parts := strings.Split(event.Line, " ")
+		if parts[1] != parts[3] {
+			t.Fatalf("Session attributes within a line differ: %q", event.Line)
+		}
+		if user, ok := users[parts[0]]; ok && user != parts[1] {
+			t.Fatalf("Session %s changed user from %s to %s", parts[0], user, parts[1])
+		}
+		users[parts[0]] = parts[1]
+
+		if event.Values["user"] != parts[1] {
+			t.Errorf("Recorded user = %q, want %q", event.Values["user"], parts[1])
+		}
+	}
+
+	if len(users) != 2 {
+		t.Errorf("Got %d sessions, want the configured concurrency of 2", len(users))
+	}
+}
+
+func TestSessionLifetime(t *testing.T) {
+	gen := newSessionGenerator(t, []config.LogTemplate{
+		{Template: `{{session "session_id"}}`, Weight: 1},
+	}, config.SessionConfig{Concurrency: 1, Lifetime: 4})
+
+	// Every session is used for between 2 and 6 lines
+	lines := make(map[string]int)
+	var order []string
+	for i := 0; i < 100; i++ {
+		line, err := gen.GenerateLogLine()
+		if err != nil {
+			t.Fatalf("GenerateLogLine failed: %v", err)
+		}
+		if lines[line] == 0 {
+			order = append(order, line)
+		}
+		lines[line]++
+	}
+
+	// The last session may not have ended yet
+	for _, id := range order[:len(order)-1] {
+		if n := lines[id]; n < 2 || n > 6 {
+			t.Errorf("Session %s used for %d lines, want between 2 and 6", id, n)
+		}
+	}
+}
+
+func TestSessionStartAndEnd(t *testing.T) {
+	gen := newSessionGenerator(t, []config.LogTemplate{
+		{Template: `login {{session "id"}}`, Weight: 1, Session: config.SessionStart},
+		{Template: `action {{session "id"}}`, Weight: 5},
+		{Template: `logout {{session "id"}}`, Weight: 1, Session: config.SessionEnd},
+	}, config.SessionConfig{
+		Concurrency: 3,
+		Lifetime:    1000,
+		Attributes:  map[string]string{"id": "{{UUID}}"},
+	})
+
+	seen := make(map[string]bool)
+	ended := make(map[string]bool)
+	logins := 0
+	for i := 0; i < 500; i++ {
+		line, err := gen.GenerateLogLine()
+		if err != nil {
+			t.Fatalf("GenerateLogLine failed: %v", err)
+		}
+		kind, id, _ := strings.Cut(line, " ")
+		if ended[id] {
+			t.Fatalf("Session %s used after its logout: %q", id, line)
+		}
+
+		switch kind {
+		case "login":
+			if seen[id] {
+				t.Fatalf("Session %s logged in after it was used", id)
+			}
+			logins++
+		case "logout":
+			ended[id] = true
+		}
+		seen[id] = true
+	}
+
+	if logins < 10 || len(ended) < 10 {
+		t.Errorf("Expected many sessions to start and end, got %d and %d", logins, len(ended))
+	}
+}
+
+func TestSessionUnknownAttribute(t *testing.T) {
+	gen := newSessionGenerator(t, []config.LogTemplate{
+		{Template: `{{session "nope"}}`, Weight: 1},
+	}, config.SessionConfig{})
+
+	if _, err := gen.GenerateLogLine(); err == nil {
+		t.Error("Expected error for an unknown session attribute, got nil")
+	}
+}
@@ -0,0 +1,419 @@
+package generator
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+	"unicode/utf8"
+
+	"github.com/brianvoe/gofakeit/v7"
+)
+
+// templates holds every template text of the configuration, parsed once.
+// The parsed templates are shared, and every worker source binds its own
+// functions to a clone of them.
+type templates struct {
+	lines        []*template.Template          // string templates, nil for templates defined as fields
+	vars         [][]*template.Template        // template variables, in the order of varNames
+	fields       [][]*template.Template        // fields of templates defined as fields
+	scenarioVars [][]*template.Template        // scenario variables, in the order of scenarioVarNames
+	attributes   map[string]*template.Template // session attributes
+}
+
+// fakerExclusions are the gofakeit methods that gofakeit.Template leaves out
+var fakerExclusions = []string{"Generate", "Template", "Markdown", "EmailText", "FixedWidth"}
+
+// fakerFuncs returns the functions gofakeit makes available to its templates,
+// drawing their values from faker. Templates are parsed and executed by the
+// generator instead of gofakeit.Template, so they are only parsed once.
+func fakerFuncs(faker *gofakeit.Faker) template.FuncMap {
+	funcMap := template.FuncMap{}
+
+	v := reflect.ValueOf(faker)
+	for i := 0; i < v.NumMethod(); i++ {
+		method := v.Type().Method(i)
+		if slices.Contains(fakerExclusions, method.Name) || method.Type.NumOut() == 0 {
+			continue
+		}
+		funcMap[method.Name] = v.Method(i).Interface()
+	}
+
+	funcMap["ToUpper"] = strings.ToUpper
+	funcMap["ToLower"] = strings.ToLower
+	funcMap["IntRange"] = func(start, end int) []int {
+		n := make([]int, 0, max(0, end-start+1))
+		for i := start; i <= end; i++ {
+			n = append(n, i)
+		}
+		return n
+	}
+	funcMap["Replace"] = strings.ReplaceAll
+	funcMap["Concat"] = func(args ...string) string {
+		return strings.Join(args, "")
+	}
+	funcMap["ToInt"] = func(arg any) int {
+		switch v := arg.(type) {
+		case string:
+			i, _ := strconv.Atoi(v)
+			return i
+		case float64:
+			return int(v)
+		case float32:
+			return int(v)
+		case int:
+			return v
+		default:
+			return 0
+		}
+	}
+	funcMap["ToFloat"] = func(arg any) float64 {
+		switch v := arg.(type) {
+		case string:
+			f, _ := strconv.ParseFloat(v, 64)
+			return f
+		case float64:
+			return v
+		case float32:
+			return float64(v)
+		case int:
+			return float64(v)
+		default:
+			return 0
+		}
+	}
+	funcMap["ToString"] = func(arg any) string {
+		return fmt.Sprint(arg)
+	}
+	funcMap["ToDate"] = func(date string) time.Time {
+		t, err := time.Parse("2006-01-02", date)
+		if err != nil {
+			return time.Now()
+		}
+		return t
+	}
+	funcMap["SpliceAny"] = func(args ...any) []any { return args }
+	funcMap["SpliceString"] = func(args ...string) []string { return args }
+	funcMap["SpliceUInt"] = func(args ...uint) []uint { return args }
+	funcMap["SpliceInt"] = func(args ...int) []int { return args }
+
+	return funcMap
+}
+
+// parseTemplates parses the templates, variables and fields of the templates,
+// the scenario variables and the session attributes. Parsing only needs the
+// names of the functions in funcMap.
+func (r *Renderer) parseTemplates(funcMap template.FuncMap) (*templates, error) {
+	// Every text is parsed, so all mistakes are reported at once
+	var errs []error
+	parse := func(name, text string) *template.Template {
+		t, err := template.New(name).Funcs(funcMap).Parse(text)
+		if err != nil {
+			errs = append(errs, newTemplateError(name, text, err, funcMap))
+		}
+		return t
+	}
+
+	t := &templates{
+		lines:        make([]*template.Template, len(r.config.Templates)),
+		vars:         make([][]*template.Template, len(r.config.Templates)),
+		fields:       make([][]*template.Template, len(r.config.Templates)),
+		scenarioVars: make([][]*template.Template, len(r.config.Scenarios)),
+		attributes:   make(map[string]*template.Template),
+	}
+	for i, tpl := range r.config.Templates {
+		for _, name := range r.varNames[i] {
+			t.vars[i] = append(t.vars[i], parse(fmt.Sprintf("template %d var %s", i, name), tpl.Vars[name]))
+		}
+		for _, field := range tpl.Fields {
+			t.fields[i] = append(t.fields[i], parse(fmt.Sprintf("template %d field %s", i, field.Name), field.Template))
+		}
+		if len(tpl.Fields) == 0 {
+			t.lines[i] = parse(fmt.Sprintf("template %d", i), tpl.Template)
+		}
+	}
+	for i, scenario := range r.config.Scenarios {
+		for _, name := range r.scenarioVarNames[i] {
+			t.scenarioVars[i] = append(t.scenarioVars[i], parse(fmt.Sprintf("scenario %s var %s", scenario.Name, name), scenario.Vars[name]))
+		}
+	}
+	attributes := sessionAttributes(r.config.Sessions)
+	for _, name := range sortedKeys(attributes) {
+		t.attributes[name] = parse("session attribute "+name, attributes[name])
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return t, nil
+}
+
+// TemplateError is an error in the text of a template, found when the
+// templates are parsed or executed
+type TemplateError struct {
+	// Source is where the text is configured, e.g. "template 2" or
+	// "template 2 field msg"
+	Source string
+
+	// Line and Column locate the error in the text, counting from 1.
+	// Column is 0 when only the line is known.
+	Line   int
+	Column int
+
+	// Function is the name of the unknown function, if that is the error
+	Function string
+
+	// Suggestions are known functions with a name close to Function
+	Suggestions []string
+
+	// Err is the error returned by the template parser or executor
+	Err error
+
+	msg string // Err without the location
+}
+
+// Error reports the location of the error, e.g.
+// template 0: line 1, column 3: function "levle" not defined, did you mean "level"?
+func (e *TemplateError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s: line %d", e.Source, e.Line)
+	if e.Column > 0 {
+		fmt.Fprintf(&b, ", column %d", e.Column)
+	}
+	b.WriteString(": ")
+
+	b.WriteString(e.msg)
+
+	for i, suggestion := range e.Suggestions {
+		switch {
+		case i == 0:
+			b.WriteString(", did you mean ")
+		case i == len(e.Suggestions)-1:
+			b.WriteString(" or ")
+		default:
+			b.WriteString(", ")
+		}
+		b.WriteString(strconv.Quote(suggestion))
+	}
+	if len(e.Suggestions) > 0 {
+		b.WriteByte('?')
+	}
+	return b.String()
+}
+
+func (e *TemplateError) Unwrap() error {
+	return e.Err
+}
+
+var (
+	// errorLocation matches the location the template parser and executor put
+	// before their errors, template: <name>:<line>:, followed by the column for
+	// the executor
+	errorLocation = regexp.MustCompile(`(?s)^template: (.*?):(\d+):(?:(\d+):)? (.*)$`)
+	// undefinedFunction matches the error of the template parser for an unknown function
+	undefinedFunction = regexp.MustCompile(`function "([^"]+)" not defined`)
+)
+
+// maxSuggestions is the number of close matches suggested for an unknown function
+const maxSuggestions = 3
+
+// newTemplateError locates the error err in the template text named name,
+// and suggests functions from funcMap for an unknown function
+func newTemplateError(name, text string, err error, funcMap template.FuncMap) *TemplateError {
+	e := &TemplateError{Source: name, Line: 1, Err: err, msg: err.Error()}
+	if m := errorLocation.FindStringSubmatch(err.Error()); m != nil && m[1] == name {
+		e.Line, _ = strconv.Atoi(m[2])
+		if m[3] != "" {
+			// The executor counts columns in bytes from 0
+			column, _ := strconv.Atoi(m[3])
+			e.Column = column + 1
+		}
+		e.msg = strings.TrimPrefix(m[4], fmt.Sprintf("executing %q at ", name))
+	}
+
+	m := undefinedFunction.FindStringSubmatch(err.Error())
+	if m == nil {
+		return e
+	}
+	e.Function = m[1]
+	e.Column = functionColumn(text, e.Line, e.Function)
+	e.Suggestions = suggestFunctions(e.Function, funcMap)
+	return e
+}
+
+// functionColumn returns the column of the first use of function within an
+// action on the given line of text, or 0 when it is not found
+func functionColumn(text string, line int, function string) int {
+	lines := strings.Split(text, "\n")
+	if line < 1 || line > len(lines) {
+		return 0
+	}
+	l := lines[line-1]
+
+	ident := regexp.MustCompile(`\b` + regexp.QuoteMeta(function) + `\b`)
+	for _, loc := range ident.FindAllStringIndex(l, -1) {
+		before := l[:loc[0]]
+		if strings.LastIndex(before, "{{") > strings.LastIndex(before, "}}") {
+			return utf8.RuneCountInString(before) + 1
+		}
+	}
+	return 0
+}
+
+// suggestFunctions returns the functions in funcMap closest to name, ignoring
+// case and allowing about one typo for every three characters
+func suggestFunctions(name string, funcMap template.FuncMap) []string {
+	type match struct {
+		name     string
+		distance int // distance ignoring case
+		exact    int // distance with case, preferring names with the same case
+	}
+	limit := max(1, utf8.RuneCountInString(name)/3)
+	var matches []match
+	for candidate := range funcMap {
+		if d := editDistance(strings.ToLower(name), strings.ToLower(candidate)); d <= limit {
+			matches = append(matches, match{candidate, d, editDistance(name, candidate)})
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].distance != matches[j].distance {
+			return matches[i].distance < matches[j].distance
+		}
+		if matches[i].exact != matches[j].exact {
+			return matches[i].exact < matches[j].exact
+		}
+		return matches[i].name < matches[j].name
+	})
+
+	suggestions := make([]string, 0, maxSuggestions)
+	for _, m := range matches {
+		if len(suggestions) == maxSuggestions {
+			break
+		}
+		suggestions = append(suggestions, m.name)
+	}
+	return suggestions
+}
+
+// editDistance returns the number of inserted, deleted, substituted and
+// swapped adjacent characters needed to turn a into b
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	// d[i][j] is the distance between the first i runes of a and the first j runes of b
+	d := make([][]int, len(ra)+1)
+	for i := range d {
+		d[i] = make([]int, len(rb)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(ra)][len(rb)]
+}
+
+// bind returns a copy of the templates that call the functions in funcMap
+func (t *templates) bind(funcMap template.FuncMap) (*templates, error) {
+	bindAll := func(parsed []*template.Template) ([]*template.Template, error) {
+		bound := make([]*template.Template, len(parsed))
+		for i, p := range parsed {
+			if p == nil {
+				continue
+			}
+			c, err := p.Clone()
+			if err != nil {
+				return nil, err
+			}
+			bound[i] = c.Funcs(funcMap)
+		}
+		return bound, nil
+	}
+
+	b := &templates{
+		vars:         make([][]*template.Template, len(t.vars)),
+		fields:       make([][]*template.Template, len(t.fields)),
+		scenarioVars: make([][]*template.Template, len(t.scenarioVars)),
+		attributes:   make(map[string]*template.Template, len(t.attributes)),
+	}
+	var err error
+	if b.lines, err = bindAll(t.lines); err != nil {
+		return nil, err
+	}
+	for i := range t.vars {
+		if b.vars[i], err = bindAll(t.vars[i]); err != nil {
+			return nil, err
+		}
+		if b.fields[i], err = bindAll(t.fields[i]); err != nil {
+			return nil, err
+		}
+	}
+	for i := range t.scenarioVars {
+		if b.scenarioVars[i], err = bindAll(t.scenarioVars[i]); err != nil {
+			return nil, err
+		}
+	}
+	for name, p := range t.attributes {
+		c, err := p.Clone()
+		if err != nil {
+			return nil, err
+		}
+		b.attributes[name] = c.Funcs(funcMap)
+	}
+	return b, nil
+}
+
+// bufferPool reuses the buffers templates are executed into. Templates can
+// be executed while another one is, e.g. a session attribute while the line
+// using it, so every execution takes its own buffer.
+var bufferPool = sync.Pool{
+	New: func() any { return new(bytes.Buffer) },
+}
+
+// execute renders t for the line l, or outside of a line when l is nil. Like
+// gofakeit.Template, an escaped \n in the output becomes a newline.
+func (s *workerSource) execute(t *template.Template, l *line) (string, error) {
+	buf := bufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	defer bufferPool.Put(buf)
+
+	previous := s.line
+	s.line = l
+	defer func() { s.line = previous }()
+
+	if err := t.Execute(buf, nil); err != nil {
+		return "", newTemplateError(t.Name(), "", err, nil)
+	}
+	if bytes.Contains(buf.Bytes(), []byte(`\n`)) {
+		return strings.ReplaceAll(buf.String(), `\n`, "\n"), nil
+	}
+	return buf.String(), nil
+}
+
+// sortedKeys returns the keys of m in order, so values are rendered in the
+// same order and seeded runs are reproducible
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,152 @@
+package generator
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/P1llus/genlog/pkg/config"
+	"github.com/brianvoe/gofakeit/v7"
+)
+
+func TestParsedTemplates(t *testing.T) {
+	cfg := &config.Config{
+		Templates: []config.LogTemplate{
+			{Template: `{{ToUpper (level)}} {{Concat "a" "b"}} {{range IntRange 1 3}}{{.}}{{end}} first\nsecond`, Weight: 1},
+		},
+		CustomTypes: map[string][]string{
+			"level": {"info"},
+		},
+		Outputs: []config.OutputConfig{
+			{
+				Type:    config.OutputTypeStdout,
+				Workers: 1,
+			},
+		},
+	}
+
+	gen, err := NewGenerator(cfg, 0)
+	if err != nil {
+		t.Fatalf("NewGenerator failed: %v", err)
+	}
+
+	// The parsed templates have the same helpers as gofakeit.Template
+	for i := 0; i < 3; i++ {
+		line, err := gen.GenerateLogLine()
+		if err != nil {
+			t.Fatalf("GenerateLogLine failed: %v", err)
+		}
+		if want := "INFO ab 123 first\nsecond"; line != want {
+			t.Errorf("GenerateLogLine() = %q, want %q", line, want)
+		}
+	}
+}
+
+func TestParseTemplatesError(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  *config.Config
+		want string
+	}{
+		{
+			name: "unknown function",
+			cfg: &config.Config{
+				Templates: []config.LogTemplate{{Template: "{{nope}}", Weight: 1}},
+			},
+			want: "template 0",
+		},
+		{
+			name: "unclosed action in a field",
+			cfg: &config.Config{
+				Templates: []config.LogTemplate{{
+					Fields: config.TemplateFields{{Name: "msg", Template: "{{Word"}},
+					Weight: 1,
+				}},
+			},
+			want: "template 0 field msg",
+		},
+		{
+			name: "unknown function in a session attribute",
+			cfg: &config.Config{
+				Templates: []config.LogTemplate{{Template: `{{session "user"}}`, Weight: 1}},
+				Sessions:  config.SessionConfig{Attributes: map[string]string{"user": "{{nope}}"}},
+			},
+			want: "session attribute user",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.cfg.Outputs = []config.OutputConfig{{Type: config.OutputTypeStdout, Workers: 1}}
+			_, err := NewGenerator(tt.cfg, 0)
+			if err == nil {
+				t.Fatal("Expected error, got nil")
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Error %q does not mention %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestTemplateError(t *testing.T) {
+	cfg := &config.Config{
+		Templates: []config.LogTemplate{
+			{Template: "{{level}} ok", Weight: 1},
+			{Template: "levle: {{level}}\n{{FormattedDate \"15:04\"}} [{{levle}}] {{message}}", Weight: 1},
+		},
+		CustomTypes: map[string][]string{
+			"level":   {"INFO"},
+			"message": {"hello"},
+		},
+		Outputs: []config.OutputConfig{
+			{
+				Type:    config.OutputTypeStdout,
+				Workers: 1,
+			},
+		},
+	}
+
+	_, err := NewGenerator(cfg, 0)
+	var tplErr *TemplateError
+	if !errors.As(err, &tplErr) {
+		t.Fatalf("Expected a TemplateError, got %v", err)
+	}
+	if tplErr.Source != "template 1" || tplErr.Line != 2 || tplErr.Column != 30 || tplErr.Function != "levle" {
+		t.Errorf("Unexpected location: %+v", *tplErr)
+	}
+	if len(tplErr.Suggestions) == 0 || tplErr.Suggestions[0] != "level" {
+		t.Errorf("Suggestions = %v, want level first", tplErr.Suggestions)
+	}
+	want := `template 1: line 2, column 30: function "levle" not defined, did you mean "level"`
+	if !strings.HasPrefix(tplErr.Error(), want) {
+		t.Errorf("Error() = %q, want prefix %q", tplErr.Error(), want)
+	}
+}
+
+func TestSuggestFunctions(t *testing.T) {
+	funcMap := fakerFuncs(gofakeit.New(1))
+	funcMap["username"] = func() string { return "" }
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "ipv4adress", want: "IPv4Address"},
+		{name: "usrname", want: "username"},
+		{name: "Usernme", want: "Username"},
+		{name: "FirstNmae", want: "FirstName"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			suggestions := suggestFunctions(tt.name, funcMap)
+			if len(suggestions) == 0 || suggestions[0] != tt.want {
+				t.Errorf("suggestFunctions(%q) = %v, want %s first", tt.name, suggestions, tt.want)
+			}
+		})
+	}
+
+	if suggestions := suggestFunctions("completely_unrelated", funcMap); len(suggestions) != 0 {
+		t.Errorf("Expected no suggestions, got %v", suggestions)
+	}
+}
@@ -0,0 +1,88 @@
+package generator
+
+import (
+	"fmt"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/P1llus/genlog/pkg/config"
+)
+
+// filetimeEpochOffset is the number of 100ns intervals between the Windows
+// FILETIME epoch, 1601-01-01, and the Unix epoch
+const filetimeEpochOffset = 116444736000000000
+
+// locationCache keeps loaded time zones, as loading one reads the zone database
+var locationCache sync.Map
+
+// loadLocation returns the location for a timezone setting, loading it only once
+func loadLocation(name string) (*time.Location, error) {
+	if loc, ok := locationCache.Load(name); ok {
+		return loc.(*time.Location), nil
+	}
+	loc, err := config.LoadTimezone(name)
+	if err != nil {
+		return nil, err
+	}
+	locationCache.Store(name, loc)
+	return loc, nil
+}
+
+// timeFuncs returns the time helpers for templates. The helpers work on the
+// timestamp returned by now, and most also accept a time as their last
+// argument so helpers can be combined, e.g. {{EpochMillis (TimeOffset "-5m")}}.
+func timeFuncs(now func() time.Time) template.FuncMap {
+	// pick returns the optional time argument or the current timestamp
+	pick := func(t []time.Time) time.Time {
+		if len(t) > 0 {
+			return t[0]
+		}
+		return now()
+	}
+
+	return template.FuncMap{
+		"Now": func() time.Time {
+			return now()
+		},
+		"FormattedDate": func(format string) string {
+			return now().Format(format)
+		},
+		"FormatTime": func(format string, t time.Time) string {
+			return t.Format(format)
+		},
+		"EpochSeconds": func(t ...time.Time) int64 {
+			return pick(t).Unix()
+		},
+		"EpochMillis": func(t ...time.Time) int64 {
+			return pick(t).UnixMilli()
+		},
+		"EpochNanos": func(t ...time.Time) int64 {
+			return pick(t).UnixNano()
+		},
+		"RFC3339Nano": func(t ...time.Time) string {
+			return pick(t).Format(time.RFC3339Nano)
+		},
+		"SyslogTime": func(t ...time.Time) string {
+			return pick(t).Format(time.Stamp)
+		},
+		"FILETIME": func(t ...time.Time) int64 {
+			ts := pick(t)
+			return ts.Unix()*10_000_000 + int64(ts.Nanosecond()/100) + filetimeEpochOffset
+		},
+		"TimeOffset": func(offset string, t ...time.Time) (time.Time, error) {
+			d, err := time.ParseDuration(offset)
+			if err != nil {
+				return time.Time{}, fmt.Errorf("invalid TimeOffset %q: %w", offset, err)
+			}
+			return pick(t).Add(d), nil
+		},
+		"TimeIn": func(timezone string, t ...time.Time) (time.Time, error) {
+			loc, err := loadLocation(timezone)
+			if err != nil {
+				return time.Time{}, err
+			}
+			return pick(t).In(loc), nil
+		},
+	}
+}
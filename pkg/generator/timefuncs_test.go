@@ -0,0 +1,141 @@
+package generator
+
+import (
+	"bytes"
+	"testing"
+	"text/template"
+	"time"
+
+	"github.com/P1llus/genlog/pkg/config"
+)
+
+func TestTimeFuncs(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 123456789, time.UTC)
+	funcs := timeFuncs(func() time.Time { return now })
+
+	tests := []struct {
+		template string
+		want     string
+	}{
+		{`{{FormattedDate "2006-01-02 15:04:05"}}`, "2024-01-01 00:00:00"},
+		{`{{EpochSeconds}}`, "1704067200"},
+		{`{{EpochMillis}}`, "1704067200123"},
+		{`{{EpochNanos}}`, "1704067200123456789"},
+		{`{{RFC3339Nano}}`, "2024-01-01T00:00:00.123456789Z"},
+		{`{{SyslogTime}}`, "Jan  1 00:00:00"},
+		{`{{FILETIME}}`, "133485408001234567"},
+		{`{{EpochSeconds (TimeOffset "-5m")}}`, "1704066900"},
+		{`{{FormatTime "15:04" (TimeOffset "90m")}}`, "01:30"},
+		{`{{RFC3339Nano (TimeIn "+05:45")}}`, "2024-01-01T05:45:00.123456789+05:45"},
+		{`{{SyslogTime (TimeIn "America/New_York" (TimeOffset "-1h"))}}`, "Dec 31 18:00:00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.template, func(t *testing.T) {
+			tmpl, err := template.New("test").Funcs(funcs).Parse(tt.template)
+			if err != nil {
+				t.Fatalf("Parse failed: %v", err)
+			}
+			var buf bytes.Buffer
+			if err := tmpl.Execute(&buf, nil); err != nil {
+				t.Fatalf("Execute failed: %v", err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("Got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTimeFuncsInvalidArguments(t *testing.T) {
+	funcs := timeFuncs(time.Now)
+	for _, text := range []string{`{{TimeOffset "5 minutes"}}`, `{{TimeIn "Mars/Olympus_Mons"}}`} {
+		tmpl := template.Must(template.New("test").Funcs(funcs).Parse(text))
+		if err := tmpl.Execute(&bytes.Buffer{}, nil); err == nil {
+			t.Errorf("Expected error executing %s, got nil", text)
+		}
+	}
+}
+
+func TestTemplateTimezone(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	cfg := &config.Config{
+		Templates: []config.LogTemplate{
+			{
+				Template: `{{FormattedDate "2006-01-02T15:04:05Z07:00"}}`,
+				Weight:   1,
+				Timezone: "+05:45",
+			},
+		},
+		Outputs: []config.OutputConfig{
+			{
+				Type:    config.OutputTypeStdout,
+				Workers: 1,
+			},
+		},
+		Timezone: "America/New_York",
+		Clock: config.ClockConfig{
+			Mode:  config.ClockModeBackfill,
+			Start: start,
+			End:   start.Add(time.Hour),
+		},
+	}
+
+	gen, err := NewGenerator(cfg, 1)
+	if err != nil {
+		t.Fatalf("NewGenerator failed: %v", err)
+	}
+
+	// The template timezone overrides the global one
+	line, err := gen.GenerateLogLine()
+	if err != nil {
+		t.Fatalf("GenerateLogLine failed: %v", err)
+	}
+	if want := "2024-01-01T05:45:00+05:45"; line != want {
+		t.Errorf("Line = %q, want %q", line, want)
+	}
+}
+
+func TestDefaultTimezoneUTC(t *testing.T) {
+	// Timestamps don't depend on the time zone of the host unless Local is set
+	local := time.Local
+	time.Local = time.FixedZone("host", 2*3600)
+	defer func() { time.Local = local }()
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		timezone string
+		want     string
+	}{
+		{timezone: "", want: "2024-01-01T00:00:00Z"},
+		{timezone: "Local", want: "2024-01-01T02:00:00+02:00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.timezone, func(t *testing.T) {
+			cfg := &config.Config{
+				Templates: []config.LogTemplate{
+					{Template: `{{FormattedDate "2006-01-02T15:04:05Z07:00"}}`, Weight: 1},
+				},
+				Timezone: tt.timezone,
+				Clock: config.ClockConfig{
+					Mode:  config.ClockModeBackfill,
+					Start: start,
+					End:   start.Add(time.Hour),
+				},
+			}
+
+			r, err := NewRenderer(cfg, 1)
+			if err != nil {
+				t.Fatalf("NewRenderer failed: %v", err)
+			}
+			line, err := r.GenerateLogLine()
+			if err != nil {
+				t.Fatalf("GenerateLogLine failed: %v", err)
+			}
+			if line != tt.want {
+				t.Errorf("Line = %q, want %q", line, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/P1llus/genlog/pkg/config"
+)
+
+// DefaultSamples is the number of lines Validate renders from every template
+const DefaultSamples = 3
+
+// Validate checks a configuration without initializing its outputs. On top of
+// Config.Validate and parsing every template, it starts every scenario and
+// renders samples lines from every template, so errors that only show up while
+// rendering, like an unknown session attribute, are found as well. Every
+// template and scenario that fails is reported, joined into a single error.
+func Validate(cfg *config.Config, samples int) error {
+	if samples <= 0 {
+		samples = DefaultSamples
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("error validating config: %w", err)
+	}
+
+	// A backfill clock spreads the sample lines over its window
+	r, err := newRenderer(cfg, samples*(len(cfg.Templates)+len(cfg.Scenarios)))
+	if err != nil {
+		return err
+	}
+	source := r.source
+
+	vars, errs := r.sampleVars(source)
+	for i := range cfg.Templates {
+		for n := 0; n < samples; n++ {
+			if _, err := r.render(source, i, source.clock.next(), vars[i]); err != nil {
+				errs = append(errs, locate(fmt.Sprintf("template %d", i), err))
+				break
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// locate returns the TemplateError in err, which names the failing template
+// text, or err prefixed with source otherwise
+func locate(source string, err error) error {
+	var tplErr *TemplateError
+	if errors.As(err, &tplErr) {
+		return tplErr
+	}
+	return fmt.Errorf("%s: %w", source, err)
+}
+
+// sampleVars starts every scenario once, and returns the variables to render
+// templates with outside of scenarios: templates used by a scenario get the
+// variables of the first scenario using them. It also returns the errors of
+// scenarios that failed to start.
+func (r *Renderer) sampleVars(source *workerSource) (map[int]map[string]string, []error) {
+	var errs []error
+	vars := make(map[int]map[string]string)
+	for i, scenario := range r.config.Scenarios {
+		run, err := r.startScenario(i, source.clock.next(), source)
+		if err != nil {
+			errs = append(errs, locate(fmt.Sprintf("scenario %s", scenario.Name), err))
+			continue
+		}
+		for _, templateIdx := range run.steps {
+			if _, ok := vars[templateIdx]; !ok {
+				vars[templateIdx] = run.vars
+			}
+		}
+	}
+	return vars, errs
+}
@@ -0,0 +1,134 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/P1llus/genlog/pkg/config"
+)
+
+func TestValidate(t *testing.T) {
+	tmpDir := t.TempDir()
+	filename := filepath.Join(tmpDir, "test.log")
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		cfg     *config.Config
+		wantErr []string
+	}{
+		{
+			name: "valid",
+			cfg: &config.Config{
+				Templates: []config.LogTemplate{
+					{Template: `{{FormattedDate "15:04:05"}} {{level}} {{session "user"}}`, Weight: 1},
+					{Name: "failed", Template: `failed {{var "ip"}}`},
+				},
+				CustomTypes: map[string][]string{
+					"level": {"INFO"},
+				},
+				Scenarios: []config.Scenario{
+					{
+						Name:   "brute_force",
+						Weight: 1,
+						Vars:   map[string]string{"ip": "{{IPv4Address}}"},
+						Steps:  []config.ScenarioStep{{Template: "failed", Repeat: 3}},
+					},
+				},
+				Clock: config.ClockConfig{Mode: config.ClockModeBackfill, Start: start, End: start.Add(time.Hour)},
+			},
+		},
+		{
+			name: "config error",
+			cfg: &config.Config{
+				Templates: []config.LogTemplate{{Template: "test", Weight: 1}},
+				Clock:     config.ClockConfig{Mode: "future"},
+			},
+			wantErr: []string{"future"},
+		},
+		{
+			name: "unknown function",
+			cfg: &config.Config{
+				Templates: []config.LogTemplate{{Template: "{{levle}}", Weight: 1}},
+				CustomTypes: map[string][]string{
+					"level": {"INFO"},
+				},
+			},
+			wantErr: []string{`template 0: line 1, column 3: function "levle" not defined, did you mean "level"?`},
+		},
+		{
+			name: "every parse error",
+			cfg: &config.Config{
+				Templates: []config.LogTemplate{
+					{Template: "{{levle}}", Weight: 1, Vars: map[string]string{"ip": "{{IPv4Adress}}"}},
+					{Weight: 1, Fields: config.TemplateFields{{Name: "msg", Template: "{{"}}},
+				},
+				CustomTypes: map[string][]string{
+					"level": {"INFO"},
+				},
+				Sessions: config.SessionConfig{Attributes: map[string]string{"user": "{{Usernme}}"}},
+			},
+			wantErr: []string{
+				`template 0 var ip: line 1, column 3: function "IPv4Adress" not defined`,
+				`template 0: line 1, column 3: function "levle" not defined`,
+				`template 1 field msg: line 1`,
+				`session attribute user: line 1, column 3: function "Usernme" not defined`,
+			},
+		},
+		{
+			name: "errors while rendering",
+			cfg: &config.Config{
+				Templates: []config.LogTemplate{
+					{Template: `{{session "usr"}}`, Weight: 1},
+					{Template: "ok", Weight: 1},
+					{Template: `{{var "ip"}}`, Weight: 1},
+				},
+			},
+			wantErr: []string{
+				`template 0: line 1, column 3: <session "usr">: error calling session: unknown session attribute "usr"`,
+				`template 2: line 1, column 3: <var "ip">: error calling var: unknown variable "ip"`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.cfg.Outputs = []config.OutputConfig{
+				{
+					Type:    config.OutputTypeFile,
+					Workers: 1,
+					Config: map[string]interface{}{
+						"filename": filename,
+					},
+				},
+			}
+
+			err := Validate(tt.cfg, 0)
+			if len(tt.wantErr) == 0 {
+				if err != nil {
+					t.Fatalf("Validate failed: %v", err)
+				}
+			} else {
+				if err == nil {
+					t.Fatal("Expected error, got nil")
+				}
+				if got := strings.Split(err.Error(), "\n"); len(got) != len(tt.wantErr) {
+					t.Fatalf("Got errors %q, want %q", got, tt.wantErr)
+				}
+				for _, want := range tt.wantErr {
+					if !strings.Contains(err.Error(), want) {
+						t.Errorf("Error %q does not contain %q", err, want)
+					}
+				}
+			}
+
+			// Outputs are not initialized
+			if _, err := os.Stat(filename); !os.IsNotExist(err) {
+				t.Errorf("Validate created the output file")
+			}
+		})
+	}
+}
@@ -0,0 +1,254 @@
+package output
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/P1llus/genlog/pkg/config"
+)
+
+// Field is a named value of an event generated from a template defined as fields
+type Field struct {
+	Name  string
+	Value string
+}
+
+// Encoder encodes the fields of an event into a log line
+type Encoder interface {
+	Encode(fields []Field) string
+}
+
+// NewEncoder creates the encoder for the configuration
+func NewEncoder(cfg config.EncoderConfig) (Encoder, error) {
+	switch cfg.Type {
+	case config.EncoderJSON:
+		return jsonEncoder{}, nil
+	case config.EncoderLogfmt:
+		return logfmtEncoder{}, nil
+	case config.EncoderCEF, config.EncoderLEEF:
+		return newCEFEncoder(cfg), nil
+	case config.EncoderKV:
+		separator := cfg.Separator
+		if separator == "" {
+			separator = " "
+		}
+		return kvEncoder{separator: separator}, nil
+	case config.EncoderCSV:
+		comma := ','
+		if cfg.Separator != "" {
+			comma, _ = utf8.DecodeRuneInString(cfg.Separator)
+		}
+		return csvEncoder{comma: comma}, nil
+	default:
+		return nil, fmt.Errorf("unsupported encoder: %s", cfg.Type)
+	}
+}
+
+// jsonEncoder encodes fields as a JSON object, keeping the order of the fields
+type jsonEncoder struct{}
+
+func (jsonEncoder) Encode(fields []Field) string {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+
+	b.WriteByte('{')
+	for i, field := range fields {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		// Encoding a string can't fail
+		_ = enc.Encode(field.Name)
+		b.Truncate(b.Len() - 1) // Encode adds a newline
+		b.WriteByte(':')
+		_ = enc.Encode(field.Value)
+		b.Truncate(b.Len() - 1)
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+// logfmtEncoder encodes fields as logfmt, quoting values that need it
+type logfmtEncoder struct{}
+
+func (logfmtEncoder) Encode(fields []Field) string {
+	var b strings.Builder
+	for i, field := range fields {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(field.Name)
+		b.WriteByte('=')
+		if needsQuoting(field.Value, " ") {
+			b.WriteString(strconv.Quote(field.Value))
+		} else {
+			b.WriteString(field.Value)
+		}
+	}
+	return b.String()
+}
+
+// kvEncoder encodes fields as key=value pairs with a configurable separator,
+// quoting values that contain the separator, spaces or quotes
+type kvEncoder struct {
+	separator string
+}
+
+// kvEscaper escapes quoted kv values
+var kvEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "\t", `\t`)
+
+func (e kvEncoder) Encode(fields []Field) string {
+	var b strings.Builder
+	for i, field := range fields {
+		if i > 0 {
+			b.WriteString(e.separator)
+		}
+		b.WriteString(field.Name)
+		b.WriteByte('=')
+		if needsQuoting(field.Value, e.separator) {
+			b.WriteByte('"')
+			b.WriteString(kvEscaper.Replace(field.Value))
+			b.WriteByte('"')
+		} else {
+			b.WriteString(field.Value)
+		}
+	}
+	return b.String()
+}
+
+// needsQuoting reports whether a key=value value must be quoted, because it
+// is empty or contains the separator, whitespace, '=', quotes or control characters
+func needsQuoting(value, separator string) bool {
+	if value == "" || strings.Contains(value, separator) {
+		return true
+	}
+	return strings.IndexFunc(value, func(r rune) bool {
+		return r == '=' || r == '"' || r == '\\' || unicode.IsSpace(r) || unicode.IsControl(r)
+	}) >= 0
+}
+
+// csvEncoder encodes the field values as a single CSV record
+type csvEncoder struct {
+	comma rune
+}
+
+func (e csvEncoder) Encode(fields []Field) string {
+	record := make([]string, len(fields))
+	for i, field := range fields {
+		record[i] = field.Value
+	}
+
+	var b strings.Builder
+	w := csv.NewWriter(&b)
+	w.Comma = e.comma
+	// Writing to a strings.Builder can't fail
+	_ = w.Write(record)
+	w.Flush()
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// cefEncoder encodes fields as CEF or LEEF. The header is filled from the
+// configuration and the event ID field, as well as the name and severity fields
+// for CEF. The other fields become the extension or LEEF attributes.
+type cefEncoder struct {
+	leef          bool
+	vendor        string
+	product       string
+	version       string
+	eventIDField  string
+	nameField     string
+	severityField string
+}
+
+func newCEFEncoder(cfg config.EncoderConfig) cefEncoder {
+	e := cefEncoder{
+		leef:          cfg.Type == config.EncoderLEEF,
+		vendor:        cfg.Vendor,
+		product:       cfg.Product,
+		version:       cfg.Version,
+		eventIDField:  cfg.EventIDField,
+		nameField:     cfg.NameField,
+		severityField: cfg.SeverityField,
+	}
+	if e.vendor == "" {
+		e.vendor = "genlog"
+	}
+	if e.product == "" {
+		e.product = "genlog"
+	}
+	if e.version == "" {
+		e.version = "1.0"
+	}
+	if e.eventIDField == "" {
+		e.eventIDField = "event_id"
+	}
+	if e.nameField == "" {
+		e.nameField = "name"
+	}
+	if e.severityField == "" {
+		e.severityField = "severity"
+	}
+	return e
+}
+
+var (
+	// cefHeaderEscaper escapes CEF and LEEF header values
+	cefHeaderEscaper = strings.NewReplacer(`\`, `\\`, `|`, `\|`, "\n", " ", "\r", " ")
+	// cefExtensionEscaper escapes CEF extension values
+	cefExtensionEscaper = strings.NewReplacer(`\`, `\\`, `=`, `\=`, "\n", `\n`, "\r", `\r`)
+	// leefAttributeEscaper replaces the tabs separating LEEF attributes and line
+	// breaks in attribute values, as LEEF has no escape sequences for them
+	leefAttributeEscaper = strings.NewReplacer("\t", " ", "\n", " ", "\r", " ")
+)
+
+func (e cefEncoder) Encode(fields []Field) string {
+	eventID, name, severity := "0", "event", "5"
+	extension := make([]Field, 0, len(fields))
+	for _, field := range fields {
+		switch {
+		case field.Name == e.eventIDField:
+			eventID = field.Value
+		case !e.leef && field.Name == e.nameField:
+			name = field.Value
+		case !e.leef && field.Name == e.severityField:
+			severity = field.Value
+		default:
+			extension = append(extension, field)
+		}
+	}
+
+	var b strings.Builder
+	if e.leef {
+		b.WriteString("LEEF:1.0|")
+	} else {
+		b.WriteString("CEF:0|")
+	}
+	header := []string{e.vendor, e.product, e.version, eventID}
+	if !e.leef {
+		header = append(header, name, severity)
+	}
+	for _, value := range header {
+		b.WriteString(cefHeaderEscaper.Replace(value))
+		b.WriteByte('|')
+	}
+
+	separator, escaper := " ", cefExtensionEscaper
+	if e.leef {
+		separator, escaper = "\t", leefAttributeEscaper
+	}
+	for i, field := range extension {
+		if i > 0 {
+			b.WriteString(separator)
+		}
+		b.WriteString(field.Name)
+		b.WriteByte('=')
+		b.WriteString(escaper.Replace(field.Value))
+	}
+	return b.String()
+}
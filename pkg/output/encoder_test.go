@@ -0,0 +1,163 @@
+package output
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/P1llus/genlog/pkg/config"
+)
+
+func TestEncoders(t *testing.T) {
+	fields := []Field{
+		{Name: "event_id", Value: "4625"},
+		{Name: "name", Value: "Logon|Failure"},
+		{Name: "severity", Value: "7"},
+		{Name: "user", Value: `bob "the admin"`},
+		{Name: "msg", Value: "a=b\tc\nd"},
+		{Name: "path", Value: `C:\Users,x`},
+	}
+
+	tests := []struct {
+		name string
+		cfg  config.EncoderConfig
+		want string
+	}{
+		{
+			name: "json",
+			cfg:  config.EncoderConfig{Type: config.EncoderJSON},
+			want: `{"event_id":"4625","name":"Logon|Failure","severity":"7","user":"bob \"the admin\"","msg":"a=b\tc\nd","path":"C:\\Users,x"}`,
+		},
+		{
+			name: "logfmt",
+			cfg:  config.EncoderConfig{Type: config.EncoderLogfmt},
+			want: `event_id=4625 name=Logon|Failure severity=7 user="bob \"the admin\"" msg="a=b\tc\nd" path="C:\\Users,x"`,
+		},
+		{
+			name: "kv",
+			cfg:  config.EncoderConfig{Type: config.EncoderKV, Separator: ", "},
+			want: `event_id=4625, name=Logon|Failure, severity=7, user="bob \"the admin\"", msg="a=b\tc\nd", path="C:\\Users,x"`,
+		},
+		{
+			name: "csv",
+			cfg:  config.EncoderConfig{Type: config.EncoderCSV},
+			want: "4625,Logon|Failure,7,\"bob \"\"the admin\"\"\",\"a=b\tc\nd\",\"C:\\Users,x\"",
+		},
+		{
+			name: "csv with separator",
+			cfg:  config.EncoderConfig{Type: config.EncoderCSV, Separator: ";"},
+			want: "4625;Logon|Failure;7;\"bob \"\"the admin\"\"\";\"a=b\tc\nd\";C:\\Users,x",
+		},
+		{
+			name: "cef",
+			cfg:  config.EncoderConfig{Type: config.EncoderCEF, Vendor: "Acme", Product: "Auth", Version: "2.1"},
+			want: `CEF:0|Acme|Auth|2.1|4625|Logon\|Failure|7|user=bob "the admin" msg=a\=b` + "\t" + `c\nd path=C:\\Users,x`,
+		},
+		{
+			name: "leef",
+			cfg:  config.EncoderConfig{Type: config.EncoderLEEF},
+			want: "LEEF:1.0|genlog|genlog|1.0|4625|name=Logon|Failure\tseverity=7\tuser=bob \"the admin\"\tmsg=a=b c d\tpath=C:\\Users,x",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			enc, err := NewEncoder(tt.cfg)
+			if err != nil {
+				t.Fatalf("NewEncoder failed: %v", err)
+			}
+			if got := enc.Encode(fields); got != tt.want {
+				t.Errorf("Encode() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCEFEncoderDefaults(t *testing.T) {
+	enc, err := NewEncoder(config.EncoderConfig{Type: config.EncoderCEF})
+	if err != nil {
+		t.Fatalf("NewEncoder failed: %v", err)
+	}
+	got := enc.Encode([]Field{{Name: "src", Value: "10.0.0.1"}})
+	want := "CEF:0|genlog|genlog|1.0|0|event|5|src=10.0.0.1"
+	if got != want {
+		t.Errorf("Encode() = %s, want %s", got, want)
+	}
+}
+
+func TestNewEncoderUnsupported(t *testing.T) {
+	if _, err := NewEncoder(config.EncoderConfig{Type: "xml"}); err == nil {
+		t.Error("Expected error for unsupported encoder, got nil")
+	}
+}
+
+// eventGenerator implements EventGenerator for testing
+type eventGenerator struct {
+	mockGenerator
+	events []Event
+}
+
+func (m *eventGenerator) GenerateEvent() (Event, error) {
+	event := m.events[m.index%len(m.events)]
+	m.index++
+	return event, nil
+}
+
+func TestWorkerEncoder(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg := config.OutputConfig{
+		Type:    config.OutputTypeFile,
+		Workers: 1,
+		Config: map[string]interface{}{
+			"filename": filepath.Join(tmpDir, "test.log"),
+		},
+	}
+	out, err := NewOutput(cfg, 0)
+	if err != nil {
+		t.Fatalf("NewOutput failed: %v", err)
+	}
+	defer out.Close()
+
+	fields := []Field{{Name: "level", Value: "INFO"}, {Name: "msg", Value: "user logged in"}}
+	line, _ := json.Marshal(map[string]string{"level": "INFO", "msg": "user logged in"})
+	gen := &eventGenerator{events: []Event{
+		{Line: string(line), Fields: fields},
+		{Line: "plain line"},
+	}}
+
+	enc, err := NewEncoder(config.EncoderConfig{Type: config.EncoderLogfmt})
+	if err != nil {
+		t.Fatalf("NewEncoder failed: %v", err)
+	}
+	stopChan := make(chan struct{})
+	worker := NewWorker(out, gen, 2, 2, stopChan)
+	worker.SetEncoder(enc)
+	go worker.Start()
+	time.Sleep(100 * time.Millisecond)
+	close(stopChan)
+
+	file, err := os.Open(cfg.Config["filename"].(string))
+	if err != nil {
+		t.Fatalf("Failed to open output file: %v", err)
+	}
+	defer file.Close()
+
+	// Events with fields are encoded again, other lines are written unchanged
+	want := []string{`level=INFO msg="user logged in"`, "plain line"}
+	var got []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		got = append(got, scanner.Text())
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Got lines %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Line %d = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,265 @@
+package output
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/P1llus/genlog/pkg/config"
+)
+
+const (
+	defaultHTTPTimeout       = 30 * time.Second
+	defaultHTTPMaxRetries    = 3
+	defaultHTTPRetryDelay    = 500 * time.Millisecond
+	defaultHTTPMaxRetryDelay = 30 * time.Second
+)
+
+// httpOutput implements Output for HTTP endpoints.
+// Every batch is sent as a single request and retried with exponential
+// backoff when the server responds with 429 or a 5xx status.
+type httpOutput struct {
+	cfg       config.HTTPOutputConfig
+	client    *http.Client
+	body      bytes.Buffer
+	gzipBody  bytes.Buffer
+	gzip      *gzip.Writer
+	action    []byte
+	closed    chan struct{}
+	closeOnce sync.Once
+	mu        sync.Mutex
+}
+
+func newHTTPOutput(cfg config.OutputConfig) (*httpOutput, error) {
+	var httpCfg config.HTTPOutputConfig
+	if err := cfg.Decode(&httpCfg); err != nil {
+		return nil, err
+	}
+	if err := httpCfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	if httpCfg.Format == "" {
+		httpCfg.Format = config.HTTPFormatNDJSON
+	}
+	if httpCfg.Method == "" {
+		httpCfg.Method = http.MethodPost
+	}
+	if httpCfg.Timeout <= 0 {
+		httpCfg.Timeout = defaultHTTPTimeout
+	}
+	if httpCfg.MaxRetries == 0 {
+		httpCfg.MaxRetries = defaultHTTPMaxRetries
+	}
+	if httpCfg.RetryDelay <= 0 {
+		httpCfg.RetryDelay = defaultHTTPRetryDelay
+	}
+	if httpCfg.MaxRetryDelay < httpCfg.RetryDelay {
+		httpCfg.MaxRetryDelay = max(defaultHTTPMaxRetryDelay, httpCfg.RetryDelay)
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if httpCfg.TLS != nil {
+		tlsConfig, err := newTLSConfig(httpCfg.TLS, "")
+		if err != nil {
+			return nil, err
+		}
+		transport.TLSClientConfig = tlsConfig
+	}
+
+	o := &httpOutput{
+		cfg: httpCfg,
+		client: &http.Client{
+			Timeout:   httpCfg.Timeout,
+			Transport: transport,
+		},
+		closed: make(chan struct{}),
+	}
+
+	if httpCfg.Compression == "gzip" {
+		o.gzip = gzip.NewWriter(&o.gzipBody)
+	}
+
+	if httpCfg.Format == config.HTTPFormatElasticsearch {
+		action := map[string]map[string]string{"index": {}}
+		if httpCfg.Index != "" {
+			action["index"]["_index"] = httpCfg.Index
+		}
+		o.action, _ = json.Marshal(action)
+	}
+
+	return o, nil
+}
+
+// writeDocument appends a line as a JSON document, wrapping lines that are not valid JSON
+func (o *httpOutput) writeDocument(msg string) {
+	if json.Valid([]byte(msg)) {
+		o.body.WriteString(msg)
+		return
+	}
+	doc, _ := json.Marshal(map[string]string{"message": msg})
+	o.body.Write(doc)
+}
+
+// encode writes the batch to the request body in the configured format
+func (o *httpOutput) encode(messages []string) error {
+	o.body.Reset()
+	switch o.cfg.Format {
+	case config.HTTPFormatJSON:
+		o.body.WriteByte('[')
+		for i, msg := range messages {
+			if i > 0 {
+				o.body.WriteByte(',')
+			}
+			o.writeDocument(msg)
+		}
+		o.body.WriteByte(']')
+	case config.HTTPFormatElasticsearch:
+		for _, msg := range messages {
+			o.body.Write(o.action)
+			o.body.WriteByte('\n')
+			o.writeDocument(msg)
+			o.body.WriteByte('\n')
+		}
+	default:
+		for _, msg := range messages {
+			o.body.WriteString(msg)
+			o.body.WriteByte('\n')
+		}
+	}
+
+	if o.gzip == nil {
+		return nil
+	}
+	o.gzipBody.Reset()
+	o.gzip.Reset(&o.gzipBody)
+	if _, err := o.gzip.Write(o.body.Bytes()); err != nil {
+		return err
+	}
+	return o.gzip.Close()
+}
+
+// contentType returns the Content-Type header matching the configured format
+func (o *httpOutput) contentType() string {
+	switch o.cfg.Format {
+	case config.HTTPFormatJSON:
+		return "application/json"
+	default:
+		return "application/x-ndjson"
+	}
+}
+
+// newRequest builds a request for the encoded batch
+func (o *httpOutput) newRequest() (*http.Request, error) {
+	body := o.body.Bytes()
+	if o.gzip != nil {
+		body = o.gzipBody.Bytes()
+	}
+
+	req, err := http.NewRequest(o.cfg.Method, o.cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", o.contentType())
+	if o.gzip != nil {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	for name, value := range o.cfg.Headers {
+		req.Header.Set(name, value)
+	}
+	if o.cfg.Username != "" {
+		req.SetBasicAuth(o.cfg.Username, o.cfg.Password)
+	}
+	if o.cfg.BearerToken != "" {
+		req.Header.Set("Authorization", "Bearer "+o.cfg.BearerToken)
+	}
+	return req, nil
+}
+
+// send performs a single request, reporting whether a failure may be retried
+// and how long the server asked to wait before doing so
+func (o *httpOutput) send() (retry bool, wait time.Duration, err error) {
+	req, err := o.newRequest()
+	if err != nil {
+		return false, 0, err
+	}
+
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return true, 0, err
+	}
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+			wait = time.Duration(seconds) * time.Second
+		}
+		return true, wait, fmt.Errorf("server responded with %s", resp.Status)
+	}
+	if resp.StatusCode >= 300 {
+		return false, 0, fmt.Errorf("server responded with %s: %s", resp.Status, bytes.TrimSpace(respBody))
+	}
+
+	if o.cfg.Format == config.HTTPFormatElasticsearch {
+		var bulk struct {
+			Errors bool `json:"errors"`
+		}
+		if err := json.Unmarshal(respBody, &bulk); err == nil && bulk.Errors {
+			return false, 0, fmt.Errorf("bulk request contained failed items")
+		}
+	}
+	return false, 0, nil
+}
+
+func (o *httpOutput) Write(messages []string) error {
+	if len(messages) == 0 {
+		return nil
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if err := o.encode(messages); err != nil {
+		return fmt.Errorf("error encoding HTTP batch: %w", err)
+	}
+
+	delay := o.cfg.RetryDelay
+	for attempt := 0; ; attempt++ {
+		retry, wait, err := o.send()
+		if err == nil {
+			return nil
+		}
+		if !retry || o.cfg.MaxRetries < 0 || attempt >= o.cfg.MaxRetries {
+			return fmt.Errorf("error sending HTTP batch: %w", err)
+		}
+
+		select {
+		case <-o.closed:
+			return fmt.Errorf("output stopped while retrying: %w", err)
+		case <-time.After(max(min(wait, o.cfg.MaxRetryDelay), delay)):
+		}
+		delay = min(delay*2, o.cfg.MaxRetryDelay)
+	}
+}
+
+// Cancel makes a write that is waiting to retry, and every later failed
+// one, give up instead of retrying
+func (o *httpOutput) Cancel() {
+	o.closeOnce.Do(func() { close(o.closed) })
+}
+
+func (o *httpOutput) Close() error {
+	o.Cancel()
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.client.CloseIdleConnections()
+	return nil
+}
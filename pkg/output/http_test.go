@@ -0,0 +1,319 @@
+package output
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/P1llus/genlog/pkg/config"
+)
+
+// captureRequests starts a test server that records the decoded body and
+// headers of every request and responds with the given status codes in order
+func captureRequests(t *testing.T, statuses ...int) (*httptest.Server, *[]string, *[]http.Header) {
+	t.Helper()
+	var (
+		bodies  []string
+		headers []http.Header
+		calls   atomic.Int32
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reader := io.Reader(r.Body)
+		if r.Header.Get("Content-Encoding") == "gzip" {
+			gz, err := gzip.NewReader(r.Body)
+			if err != nil {
+				t.Errorf("Invalid gzip body: %v", err)
+				return
+			}
+			reader = gz
+		}
+		body, _ := io.ReadAll(reader)
+		bodies = append(bodies, string(body))
+		headers = append(headers, r.Header.Clone())
+
+		call := int(calls.Add(1)) - 1
+		if call < len(statuses) {
+			w.WriteHeader(statuses[call])
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, &bodies, &headers
+}
+
+func TestHTTPOutputFormats(t *testing.T) {
+	messages := []string{`{"msg":"structured"}`, "plain text"}
+
+	tests := []struct {
+		name   string
+		config map[string]interface{}
+		want   string
+	}{
+		{
+			name:   "ndjson",
+			config: map[string]interface{}{},
+			want:   "{\"msg\":\"structured\"}\nplain text\n",
+		},
+		{
+			name:   "json array",
+			config: map[string]interface{}{"format": "json"},
+			want:   `[{"msg":"structured"},{"message":"plain text"}]`,
+		},
+		{
+			name:   "elasticsearch bulk",
+			config: map[string]interface{}{"format": "elasticsearch", "index": "logs-genlog"},
+			want: "{\"index\":{\"_index\":\"logs-genlog\"}}\n{\"msg\":\"structured\"}\n" +
+				"{\"index\":{\"_index\":\"logs-genlog\"}}\n{\"message\":\"plain text\"}\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, bodies, _ := captureRequests(t)
+			tt.config["url"] = server.URL
+
+			out, err := NewOutput(config.OutputConfig{Type: config.OutputTypeHTTP, Workers: 1, Config: tt.config}, 0)
+			if err != nil {
+				t.Fatalf("NewOutput failed: %v", err)
+			}
+			defer out.Close()
+
+			if err := out.Write(messages); err != nil {
+				t.Fatalf("Write failed: %v", err)
+			}
+			if len(*bodies) != 1 {
+				t.Fatalf("Expected 1 request, got %d", len(*bodies))
+			}
+			if (*bodies)[0] != tt.want {
+				t.Errorf("Body mismatch:\ngot:  %q\nwant: %q", (*bodies)[0], tt.want)
+			}
+		})
+	}
+}
+
+func TestHTTPOutputHeadersAndAuth(t *testing.T) {
+	server, bodies, headers := captureRequests(t)
+
+	cfg := config.OutputConfig{
+		Type:    config.OutputTypeHTTP,
+		Workers: 1,
+		Config: map[string]interface{}{
+			"url":         server.URL,
+			"username":    "elastic",
+			"password":    "changeme",
+			"compression": "gzip",
+			"headers": map[string]interface{}{
+				"X-Test": "genlog",
+			},
+		},
+	}
+
+	out, err := NewOutput(cfg, 0)
+	if err != nil {
+		t.Fatalf("NewOutput failed: %v", err)
+	}
+	defer out.Close()
+
+	if err := out.Write([]string{"compressed line"}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	h := (*headers)[0]
+	if h.Get("X-Test") != "genlog" {
+		t.Errorf("Expected custom header, got %q", h.Get("X-Test"))
+	}
+	if !strings.HasPrefix(h.Get("Authorization"), "Basic ") {
+		t.Errorf("Expected basic auth, got %q", h.Get("Authorization"))
+	}
+	if h.Get("Content-Encoding") != "gzip" {
+		t.Errorf("Expected gzip encoding, got %q", h.Get("Content-Encoding"))
+	}
+	if (*bodies)[0] != "compressed line\n" {
+		t.Errorf("Unexpected decompressed body: %q", (*bodies)[0])
+	}
+}
+
+func TestHTTPOutputBearerToken(t *testing.T) {
+	server, _, headers := captureRequests(t)
+
+	cfg := config.OutputConfig{
+		Type:    config.OutputTypeHTTP,
+		Workers: 1,
+		Config: map[string]interface{}{
+			"url":          server.URL,
+			"bearer_token": "secret",
+		},
+	}
+
+	out, err := NewOutput(cfg, 0)
+	if err != nil {
+		t.Fatalf("NewOutput failed: %v", err)
+	}
+	defer out.Close()
+
+	if err := out.Write([]string{"line"}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if got := (*headers)[0].Get("Authorization"); got != "Bearer secret" {
+		t.Errorf("Expected bearer token, got %q", got)
+	}
+}
+
+func TestHTTPOutputRetries(t *testing.T) {
+	server, bodies, _ := captureRequests(t, http.StatusServiceUnavailable, http.StatusTooManyRequests, http.StatusOK)
+
+	cfg := config.OutputConfig{
+		Type:    config.OutputTypeHTTP,
+		Workers: 1,
+		Config: map[string]interface{}{
+			"url":         server.URL,
+			"retry_delay": "1ms",
+		},
+	}
+
+	out, err := NewOutput(cfg, 0)
+	if err != nil {
+		t.Fatalf("NewOutput failed: %v", err)
+	}
+	defer out.Close()
+
+	if err := out.Write([]string{"retried line"}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if len(*bodies) != 3 {
+		t.Fatalf("Expected 3 attempts, got %d", len(*bodies))
+	}
+	for i, body := range *bodies {
+		if body != "retried line\n" {
+			t.Errorf("Attempt %d sent %q", i, body)
+		}
+	}
+}
+
+func TestHTTPOutputRetryAfterIsClamped(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.Header().Set("Retry-After", "60")
+			w.WriteHeader(http.StatusTooManyRequests)
+		}
+	}))
+	defer server.Close()
+
+	cfg := config.OutputConfig{
+		Type:    config.OutputTypeHTTP,
+		Workers: 1,
+		Config: map[string]interface{}{
+			"url":             server.URL,
+			"retry_delay":     "1ms",
+			"max_retry_delay": "10ms",
+		},
+	}
+
+	out, err := NewOutput(cfg, 0)
+	if err != nil {
+		t.Fatalf("NewOutput failed: %v", err)
+	}
+	defer out.Close()
+
+	start := time.Now()
+	if err := out.Write([]string{"throttled line"}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Write waited %v, want at most max_retry_delay", elapsed)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("Expected 2 attempts, got %d", calls.Load())
+	}
+}
+
+func TestHTTPOutputNoRetryOnClientError(t *testing.T) {
+	server, bodies, _ := captureRequests(t, http.StatusBadRequest)
+
+	cfg := config.OutputConfig{
+		Type:    config.OutputTypeHTTP,
+		Workers: 1,
+		Config: map[string]interface{}{
+			"url":         server.URL,
+			"retry_delay": "1ms",
+		},
+	}
+
+	out, err := NewOutput(cfg, 0)
+	if err != nil {
+		t.Fatalf("NewOutput failed: %v", err)
+	}
+	defer out.Close()
+
+	if err := out.Write([]string{"bad line"}); err == nil {
+		t.Error("Expected error for 400 response, got nil")
+	}
+	if len(*bodies) != 1 {
+		t.Errorf("Expected a single attempt, got %d", len(*bodies))
+	}
+}
+
+func TestHTTPOutputBulkItemErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{"errors": true, "items": []any{}})
+	}))
+	defer server.Close()
+
+	cfg := config.OutputConfig{
+		Type:    config.OutputTypeHTTP,
+		Workers: 1,
+		Config: map[string]interface{}{
+			"url":    server.URL,
+			"format": "elasticsearch",
+		},
+	}
+
+	out, err := NewOutput(cfg, 0)
+	if err != nil {
+		t.Fatalf("NewOutput failed: %v", err)
+	}
+	defer out.Close()
+
+	if err := out.Write([]string{`{"a":1}`}); err == nil {
+		t.Error("Expected error for bulk response with failed items, got nil")
+	}
+}
+
+func TestWorkerHTTPBatches(t *testing.T) {
+	server, bodies, _ := captureRequests(t)
+
+	cfg := config.OutputConfig{
+		Type:    config.OutputTypeHTTP,
+		Workers: 1,
+		Config: map[string]interface{}{
+			"url": server.URL,
+		},
+	}
+
+	out, err := NewOutput(cfg, 0)
+	if err != nil {
+		t.Fatalf("NewOutput failed: %v", err)
+	}
+	defer out.Close()
+
+	gen := &mockGenerator{lines: []string{"line"}}
+	worker := NewWorker(out, gen, 4, 10, make(chan struct{}))
+	worker.Start()
+
+	// 10 lines with a batch size of 4 are sent as 4, 4 and 2 lines
+	want := []int{4, 4, 2}
+	if len(*bodies) != len(want) {
+		t.Fatalf("Expected %d requests, got %d", len(want), len(*bodies))
+	}
+	for i, body := range *bodies {
+		if got := strings.Count(body, "\n"); got != want[i] {
+			t.Errorf("Request %d contained %d lines, want %d", i, got, want[i])
+		}
+	}
+}
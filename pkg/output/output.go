@@ -3,14 +3,18 @@ package output
 
 import (
 	"bufio"
+	"compress/gzip"
 	"fmt"
+	"io"
 	"net"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/P1llus/genlog/pkg/config"
+	"github.com/klauspost/compress/zstd"
 )
 
 // Output represents a destination for log messages
@@ -21,11 +25,46 @@ type Output interface {
 	Close() error
 }
 
+// Canceler is implemented by outputs that retry failed writes. Cancel makes
+// them give up retrying, so stopping a worker doesn't wait for a destination
+// that can't be reached.
+type Canceler interface {
+	Cancel()
+}
+
 // LogGenerator represents the interface needed for generating log lines
 type LogGenerator interface {
 	GenerateLogLine() (string, error)
 }
 
+// Event is a rendered log line together with the values that were
+// selected while rendering it
+type Event struct {
+	// Line is the rendered log line
+	Line string
+	// Values maps custom type and template field names to the value used in Line
+	Values map[string]string
+	// Fields are the fields of templates defined as fields, in order. Line
+	// holds them encoded as JSON, and outputs with an encoder encode them again.
+	Fields []Field
+	// Time is the timestamp of the line, as chosen by the clock. Outputs such
+	// as syslog use it in their envelope. It is zero for plain log lines.
+	Time time.Time
+}
+
+// EventGenerator is implemented by generators that can report the values
+// behind every log line they generate
+type EventGenerator interface {
+	GenerateEvent() (Event, error)
+}
+
+// EventOutput is implemented by outputs that make use of the values behind
+// each log line, such as syslog deriving the severity from a level field.
+// Workers call WriteEvents instead of Write for these outputs.
+type EventOutput interface {
+	WriteEvents(events []Event) error
+}
+
 // NewOutput creates a new output based on the configuration
 func NewOutput(cfg config.OutputConfig, workerID int) (Output, error) {
 	switch cfg.Type {
@@ -33,6 +72,16 @@ func NewOutput(cfg config.OutputConfig, workerID int) (Output, error) {
 		return newFileOutput(cfg, workerID)
 	case config.OutputTypeUDP:
 		return newUDPOutput(cfg)
+	case config.OutputTypeTCP:
+		return newTCPOutput(cfg)
+	case config.OutputTypeSyslog:
+		return newSyslogOutput(cfg)
+	case config.OutputTypeHTTP:
+		return newHTTPOutput(cfg)
+	case config.OutputTypeStdout:
+		return newStreamOutput(os.Stdout, &stdoutMu), nil
+	case config.OutputTypeStderr:
+		return newStreamOutput(os.Stderr, &stderrMu), nil
 	default:
 		return nil, fmt.Errorf("unsupported output type: %s", cfg.Type)
 	}
@@ -40,35 +89,123 @@ func NewOutput(cfg config.OutputConfig, workerID int) (Output, error) {
 
 // fileOutput implements Output for file destinations
 type fileOutput struct {
-	file     *os.File
-	writer   *bufio.Writer
-	filename string
-	mu       sync.Mutex
+	cfg        config.FileOutputConfig
+	file       *os.File
+	compressor io.WriteCloser // gzip or zstd stream between writer and file, nil for plain files
+	writer     *bufio.Writer
+	filename   string
+	base       string    // filename without the compression extension, used to name backups
+	size       int64     // bytes written to the current file, before compression
+	openedAt   time.Time // when the current file was started, for time based rotation
+	mu         sync.Mutex
 }
 
 func newFileOutput(cfg config.OutputConfig, workerID int) (*fileOutput, error) {
-	filename, ok := cfg.Config["filename"].(string)
-	if !ok {
-		return nil, fmt.Errorf("filename is required for file output")
+	var fileCfg config.FileOutputConfig
+	if err := cfg.Decode(&fileCfg); err != nil {
+		return nil, err
+	}
+	if err := fileCfg.Validate(); err != nil {
+		return nil, err
+	}
+	filename := fileCfg.Filename
+	if fileCfg.RotateMode == "" {
+		fileCfg.RotateMode = config.RotateModeRename
+	}
+	fileCfg.Compression = config.ResolveFileCompression(fileCfg.Compression, filename)
+
+	// Keep the compression extension at the end, e.g. app.log.gz becomes app_worker0.log.gz
+	compressionExt := fileCfg.Compression.Extension()
+	base := filename
+	if compressionExt != "" && strings.HasSuffix(filename, compressionExt) {
+		base = strings.TrimSuffix(filename, compressionExt)
+	} else {
+		compressionExt = ""
 	}
 
 	// If there are multiple workers, append worker ID to filename
 	if cfg.Workers > 1 {
-		ext := filepath.Ext(filename)
-		base := filename[:len(filename)-len(ext)]
-		filename = fmt.Sprintf("%s_worker%d%s", base, workerID, ext)
+		ext := filepath.Ext(base)
+		base = fmt.Sprintf("%s_worker%d%s", base[:len(base)-len(ext)], workerID, ext)
 	}
 
-	file, err := os.Create(filename)
+	o := &fileOutput{
+		cfg:      fileCfg,
+		filename: base + compressionExt,
+		base:     base,
+	}
+	if err := o.open(); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+// open creates or, in append mode, opens the output file and resets the rotation counters
+func (o *fileOutput) open() error {
+	if o.cfg.Mkdir {
+		if err := os.MkdirAll(filepath.Dir(o.filename), 0o755); err != nil {
+			return fmt.Errorf("error creating output directory: %w", err)
+		}
+	}
+
+	flags := os.O_RDWR | os.O_CREATE | os.O_TRUNC
+	if o.cfg.Append {
+		flags = os.O_RDWR | os.O_CREATE | os.O_APPEND
+	}
+	mode := os.FileMode(0o666)
+	if o.cfg.Mode != 0 {
+		mode = os.FileMode(o.cfg.Mode)
+	}
+
+	file, err := os.OpenFile(o.filename, flags, mode)
 	if err != nil {
-		return nil, fmt.Errorf("error creating output file: %w", err)
+		return fmt.Errorf("error creating output file: %w", err)
 	}
 
-	return &fileOutput{
-		file:     file,
-		writer:   bufio.NewWriter(file),
-		filename: filename,
-	}, nil
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("error reading output file: %w", err)
+	}
+
+	// Appending to a compressed file starts a new gzip member or zstd frame,
+	// which decompressors read as one continuous stream
+	var w io.Writer = file
+	o.compressor = nil
+	switch o.cfg.Compression {
+	case config.FileCompressionGzip:
+		o.compressor = gzip.NewWriter(file)
+	case config.FileCompressionZstd:
+		encoder, err := zstd.NewWriter(file)
+		if err != nil {
+			file.Close()
+			return fmt.Errorf("error creating zstd encoder: %w", err)
+		}
+		o.compressor = encoder
+	}
+	if o.compressor != nil {
+		w = o.compressor
+	}
+
+	o.file = file
+	o.writer = bufio.NewWriter(w)
+	o.size = info.Size()
+	o.openedAt = time.Now()
+	return nil
+}
+
+// closeFile flushes buffered data, finishes the compressed stream and closes the current file
+func (o *fileOutput) closeFile() error {
+	err := o.writer.Flush()
+	if o.compressor != nil {
+		if closeErr := o.compressor.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if closeErr := o.file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
 }
 
 func (o *fileOutput) Write(messages []string) error {
@@ -76,9 +213,16 @@ func (o *fileOutput) Write(messages []string) error {
 	defer o.mu.Unlock()
 
 	for _, msg := range messages {
+		n := int64(len(msg) + 1)
+		if o.shouldRotate(n) {
+			if err := o.rotate(); err != nil {
+				return fmt.Errorf("error rotating file: %w", err)
+			}
+		}
 		if _, err := o.writer.WriteString(msg + "\n"); err != nil {
 			return fmt.Errorf("error writing to file: %w", err)
 		}
+		o.size += n
 	}
 	return o.writer.Flush()
 }
@@ -87,10 +231,43 @@ func (o *fileOutput) Close() error {
 	o.mu.Lock()
 	defer o.mu.Unlock()
 
-	if err := o.writer.Flush(); err != nil {
-		return err
+	return o.closeFile()
+}
+
+// stdoutMu and stderrMu serialize writes from all workers sharing a stream,
+// so batches from different workers never interleave mid-line
+var stdoutMu, stderrMu sync.Mutex
+
+// streamOutput implements Output for the standard output streams
+type streamOutput struct {
+	writer *bufio.Writer
+	mu     *sync.Mutex
+}
+
+func newStreamOutput(stream *os.File, mu *sync.Mutex) *streamOutput {
+	return &streamOutput{
+		writer: bufio.NewWriter(stream),
+		mu:     mu,
 	}
-	return o.file.Close()
+}
+
+func (o *streamOutput) Write(messages []string) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	for _, msg := range messages {
+		if _, err := o.writer.WriteString(msg + "\n"); err != nil {
+			return fmt.Errorf("error writing to stream: %w", err)
+		}
+	}
+	return o.writer.Flush()
+}
+
+// Close flushes any buffered output but leaves the stream itself open
+func (o *streamOutput) Close() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.writer.Flush()
 }
 
 // udpOutput implements Output for UDP destinations
@@ -102,11 +279,18 @@ type udpOutput struct {
 }
 
 func newUDPOutput(cfg config.OutputConfig) (*udpOutput, error) {
-	addrStr, ok := cfg.Config["address"].(string)
-	if !ok {
-		return nil, fmt.Errorf("address is required for UDP output")
+	var udpCfg config.UDPOutputConfig
+	if err := cfg.Decode(&udpCfg); err != nil {
+		return nil, err
+	}
+	if err := udpCfg.Validate(); err != nil {
+		return nil, err
 	}
+	return dialUDP(udpCfg.Address)
+}
 
+// dialUDP opens a UDP output to the given address
+func dialUDP(addrStr string) (*udpOutput, error) {
 	addr, err := net.ResolveUDPAddr("udp", addrStr)
 	if err != nil {
 		return nil, fmt.Errorf("error resolving UDP address: %w", err)
@@ -171,6 +355,10 @@ type Worker struct {
 	batchSize int
 	maxCount  int
 	stopChan  chan struct{}
+	limiters  []*RateLimiter
+	encoder   Encoder
+	lines     []string
+	err       error // error that stopped the worker
 }
 
 // NewWorker creates a new worker instance
@@ -184,10 +372,52 @@ func NewWorker(output Output, gen LogGenerator, batchSize, maxCount int, stopCha
 	}
 }
 
+// SetRateLimiters makes the worker wait for every given limiter before
+// generating an event. Limiters shared between workers limit their combined rate.
+func (w *Worker) SetRateLimiters(limiters ...*RateLimiter) {
+	w.limiters = limiters
+}
+
+// SetEncoder makes the worker encode the fields of events with encoder,
+// replacing their line
+func (w *Worker) SetEncoder(encoder Encoder) {
+	w.encoder = encoder
+}
+
+// generate produces the next event, using the generator's values when
+// it is able to report them
+func (w *Worker) generate() (Event, error) {
+	if gen, ok := w.generator.(EventGenerator); ok {
+		return gen.GenerateEvent()
+	}
+	line, err := w.generator.GenerateLogLine()
+	return Event{Line: line}, err
+}
+
+// write sends a batch to the output, passing the full events to outputs that use them
+func (w *Worker) write(batch []Event) error {
+	if w.encoder != nil {
+		for i := range batch {
+			if len(batch[i].Fields) > 0 {
+				batch[i].Line = w.encoder.Encode(batch[i].Fields)
+			}
+		}
+	}
+	if out, ok := w.Output.(EventOutput); ok {
+		return out.WriteEvents(batch)
+	}
+	w.lines = w.lines[:0]
+	for _, event := range batch {
+		w.lines = append(w.lines, event.Line)
+	}
+	return w.Output.Write(w.lines)
+}
+
 // Start begins the worker's log generation and sending process
 func (w *Worker) Start() {
-	batch := make([]string, 0, w.batchSize)
+	batch := make([]Event, 0, w.batchSize)
 	ticker := time.NewTicker(100 * time.Millisecond) // Adjust batch timing as needed
+	defer ticker.Stop()
 	count := 0
 
 	for {
@@ -195,36 +425,96 @@ func (w *Worker) Start() {
 		case <-w.stopChan:
 			// Flush any remaining logs
 			if len(batch) > 0 {
-				if err := w.Output.Write(batch); err != nil {
-					fmt.Printf("Error writing final batch: %v\n", err)
+				if err := w.write(batch); err != nil {
+					fmt.Fprintf(os.Stderr, "Error writing final batch: %v\n", err)
 				}
 			}
 			return
 		case <-ticker.C:
-			if len(batch) >= w.batchSize {
-				if err := w.Output.Write(batch); err != nil {
-					fmt.Printf("Error writing batch: %v\n", err)
-				}
-				batch = batch[:0]
-			}
+			// Flush partial batches so slow generation still reaches the output
+			batch = w.flush(batch)
 		default:
 			if w.maxCount > 0 && count >= w.maxCount {
 				// We've reached our count limit
 				if len(batch) > 0 {
-					if err := w.Output.Write(batch); err != nil {
-						fmt.Printf("Error writing final batch: %v\n", err)
+					if err := w.write(batch); err != nil {
+						fmt.Fprintf(os.Stderr, "Error writing final batch: %v\n", err)
 					}
 				}
 				return
 			}
 
-			logLine, err := w.generator.GenerateLogLine()
-			if err != nil {
-				fmt.Printf("Error generating log line: %v\n", err)
+			if !w.wait(ticker, &batch) {
 				continue
 			}
-			batch = append(batch, logLine)
+
+			// Rendering errors, such as an unknown variable, would fail every
+			// line of the template again, so the worker stops instead
+			event, err := w.generate()
+			if err != nil {
+				w.err = err
+				w.flush(batch)
+				return
+			}
+			batch = append(batch, event)
 			count++
+
+			if len(batch) >= w.batchSize {
+				if err := w.write(batch); err != nil {
+					fmt.Fprintf(os.Stderr, "Error writing batch: %v\n", err)
+				}
+				batch = batch[:0]
+			}
+		}
+	}
+}
+
+// Err returns the error that stopped the worker before it was stopped or
+// reached its count, if any. It is only valid once Start has returned.
+func (w *Worker) Err() error {
+	return w.err
+}
+
+// flush writes a partial batch and returns it emptied
+func (w *Worker) flush(batch []Event) []Event {
+	if len(batch) > 0 {
+		if err := w.write(batch); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing batch: %v\n", err)
+		}
+	}
+	return batch[:0]
+}
+
+// wait takes a token from every rate limiter and sleeps until the next event
+// may be generated. It returns false when the worker is stopped while waiting.
+func (w *Worker) wait(ticker *time.Ticker, batch *[]Event) bool {
+	for _, limiter := range w.limiters {
+		for {
+			delay, ok := limiter.Reserve()
+			if delay > 0 && !w.sleep(delay, ticker, batch) {
+				return false
+			}
+			if ok {
+				break
+			}
+		}
+	}
+	return true
+}
+
+// sleep pauses the worker, still flushing partial batches on the ticker meanwhile.
+// It returns false when the worker is stopped while sleeping.
+func (w *Worker) sleep(delay time.Duration, ticker *time.Ticker, batch *[]Event) bool {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	for {
+		select {
+		case <-w.stopChan:
+			return false
+		case <-ticker.C:
+			*batch = w.flush(*batch)
+		case <-timer.C:
+			return true
 		}
 	}
 }
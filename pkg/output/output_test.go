@@ -2,9 +2,11 @@ package output
 
 import (
 	"bufio"
+	"errors"
 	"net"
 	"os"
 	"path/filepath"
+	"sync"
 	"testing"
 	"time"
 
@@ -133,6 +135,51 @@ func TestUDPOutput(t *testing.T) {
 	}
 }
 
+func TestStreamOutput(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	var mu sync.Mutex
+	out := newStreamOutput(w, &mu)
+
+	messages := []string{
+		"test message 1",
+		"test message 2",
+	}
+	if err := out.Write(messages); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if err := out.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	w.Close()
+
+	scanner := bufio.NewScanner(r)
+	for i, msg := range messages {
+		if !scanner.Scan() {
+			t.Fatalf("Expected to read message %d", i)
+		}
+		if scanner.Text() != msg {
+			t.Errorf("Message %d mismatch: got %s, want %s", i, scanner.Text(), msg)
+		}
+	}
+}
+
+func TestNewStdoutOutput(t *testing.T) {
+	for _, outputType := range []config.OutputType{config.OutputTypeStdout, config.OutputTypeStderr} {
+		out, err := NewOutput(config.OutputConfig{Type: outputType, Workers: 1}, 0)
+		if err != nil {
+			t.Fatalf("NewOutput(%s) failed: %v", outputType, err)
+		}
+		if err := out.Close(); err != nil {
+			t.Errorf("Close(%s) failed: %v", outputType, err)
+		}
+	}
+}
+
 func TestWorker(t *testing.T) {
 	// Create a temporary directory for test files
 	tmpDir, err := os.MkdirTemp("", "worker-test-*")
@@ -195,6 +242,194 @@ func TestWorker(t *testing.T) {
 	}
 }
 
+// failingGenerator generates its lines and then fails
+type failingGenerator struct {
+	mockGenerator
+	err error
+}
+
+func (m *failingGenerator) GenerateLogLine() (string, error) {
+	if m.index >= len(m.lines) {
+		return "", m.err
+	}
+	return m.mockGenerator.GenerateLogLine()
+}
+
+func TestWorkerStopsOnGenerateError(t *testing.T) {
+	cfg := config.OutputConfig{
+		Type:    config.OutputTypeFile,
+		Workers: 1,
+		Config: map[string]interface{}{
+			"filename": filepath.Join(t.TempDir(), "test.log"),
+		},
+	}
+	out, err := NewOutput(cfg, 0)
+	if err != nil {
+		t.Fatalf("NewOutput failed: %v", err)
+	}
+	defer out.Close()
+
+	errUnknown := errors.New(`unknown variable "user"`)
+	gen := &failingGenerator{mockGenerator: mockGenerator{lines: []string{"line 1", "line 2"}}, err: errUnknown}
+	worker := NewWorker(out, gen, 10, 0, make(chan struct{}))
+
+	// The worker returns by itself, without being stopped or reaching a count
+	done := make(chan struct{})
+	go func() {
+		worker.Start()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Worker did not stop after failing to generate a line")
+	}
+	if !errors.Is(worker.Err(), errUnknown) {
+		t.Errorf("Err() = %v, want %v", worker.Err(), errUnknown)
+	}
+
+	// Lines generated before the error are still written
+	out.Close()
+	data, err := os.ReadFile(cfg.Config["filename"].(string))
+	if err != nil {
+		t.Fatalf("Failed to read output file: %v", err)
+	}
+	if string(data) != "line 1\nline 2\n" {
+		t.Errorf("Output = %q, want the lines before the error", data)
+	}
+}
+
+func TestFileOutputAppendModeMkdir(t *testing.T) {
+	// Create a temporary directory for test files
+	tmpDir, err := os.MkdirTemp("", "output-test-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	filename := filepath.Join(tmpDir, "nested", "dir", "app.log")
+	cfg := config.OutputConfig{
+		Type:    config.OutputTypeFile,
+		Workers: 1,
+		Config: map[string]interface{}{
+			"filename": filename,
+			"append":   true,
+			"mode":     "0600",
+			"mkdir":    true,
+		},
+	}
+
+	// Write twice with separate outputs, the second run should append
+	for _, msg := range []string{"first run", "second run"} {
+		out, err := NewOutput(cfg, 0)
+		if err != nil {
+			t.Fatalf("NewOutput failed: %v", err)
+		}
+		if err := out.Write([]string{msg}); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+		if err := out.Close(); err != nil {
+			t.Fatalf("Close failed: %v", err)
+		}
+	}
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("Failed to read output file: %v", err)
+	}
+	if got, want := string(data), "first run\nsecond run\n"; got != want {
+		t.Errorf("File content = %q, want %q", got, want)
+	}
+
+	info, err := os.Stat(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("File mode = %o, want 600", perm)
+	}
+}
+
+func TestCompressedFileOutput(t *testing.T) {
+	// Create a temporary directory for test files
+	tmpDir, err := os.MkdirTemp("", "output-test-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	tests := []struct {
+		name     string
+		config   map[string]interface{}
+		workers  int
+		wantFile string
+	}{
+		{
+			name:     "gzip from extension",
+			config:   map[string]interface{}{"filename": filepath.Join(tmpDir, "inferred.log.gz")},
+			workers:  1,
+			wantFile: "inferred.log.gz",
+		},
+		{
+			name:     "zstd from extension with workers",
+			config:   map[string]interface{}{"filename": filepath.Join(tmpDir, "workers.log.zst")},
+			workers:  2,
+			wantFile: "workers_worker1.log.zst",
+		},
+		{
+			name: "explicit gzip",
+			config: map[string]interface{}{
+				"filename":    filepath.Join(tmpDir, "explicit.gz"),
+				"compression": "gzip",
+				"append":      true,
+			},
+			workers:  1,
+			wantFile: "explicit.gz",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.OutputConfig{
+				Type:    config.OutputTypeFile,
+				Workers: tt.workers,
+				Config:  tt.config,
+			}
+
+			out, err := NewOutput(cfg, tt.workers-1)
+			if err != nil {
+				t.Fatalf("NewOutput failed: %v", err)
+			}
+			if err := out.Write([]string{"test message 1", "test message 2"}); err != nil {
+				t.Fatalf("Write failed: %v", err)
+			}
+			if err := out.Close(); err != nil {
+				t.Fatalf("Close failed: %v", err)
+			}
+
+			got := readCompressed(t, filepath.Join(tmpDir, tt.wantFile))
+			if want := "test message 1\ntest message 2\n"; got != want {
+				t.Errorf("Decompressed content = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestCompressedFileOutputRejectsCopyTruncate(t *testing.T) {
+	cfg := config.OutputConfig{
+		Type:    config.OutputTypeFile,
+		Workers: 1,
+		Config: map[string]interface{}{
+			"filename":    filepath.Join(os.TempDir(), "copytruncate.log.gz"),
+			"rotate_mode": "copytruncate",
+		},
+	}
+
+	if _, err := NewOutput(cfg, 0); err == nil {
+		t.Error("Expected error for copytruncate with compression, got nil")
+	}
+}
+
 func TestInvalidOutputType(t *testing.T) {
 	cfg := config.OutputConfig{
 		Type:    "invalid",
@@ -231,6 +466,14 @@ func TestMissingConfig(t *testing.T) {
 				Config:  map[string]interface{}{},
 			},
 		},
+		{
+			name: "missing TCP address",
+			cfg: config.OutputConfig{
+				Type:    config.OutputTypeTCP,
+				Workers: 1,
+				Config:  map[string]interface{}{},
+			},
+		},
 	}
 
 	for _, tt := range tests {
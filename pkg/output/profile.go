@@ -0,0 +1,76 @@
+package output
+
+import (
+	"math"
+	"time"
+
+	"github.com/P1llus/genlog/pkg/config"
+)
+
+const defaultSineAmplitude = 0.5
+
+// loadProfile computes the rate multiplier of a configured load profile
+type loadProfile struct {
+	cfg   config.LoadProfile
+	daily []float64      // hourly weights normalized to an average of 1
+	loc   *time.Location // time zone of the daily hours
+}
+
+// newLoadProfile creates a profile whose daily weights follow the hours of loc,
+// or UTC when loc is nil
+func newLoadProfile(cfg config.LoadProfile, loc *time.Location) *loadProfile {
+	if loc == nil {
+		loc = time.UTC
+	}
+	p := &loadProfile{cfg: cfg, loc: loc}
+	if p.cfg.Sine != nil && p.cfg.Sine.Amplitude == 0 {
+		sine := *p.cfg.Sine
+		sine.Amplitude = defaultSineAmplitude
+		p.cfg.Sine = &sine
+	}
+
+	if len(cfg.Daily) == 24 {
+		total := 0.0
+		for _, weight := range cfg.Daily {
+			total += weight
+		}
+		p.daily = make([]float64, 24)
+		for i, weight := range cfg.Daily {
+			p.daily[i] = weight / total * 24
+		}
+	}
+	return p
+}
+
+// factor returns the multiplier at now for a profile that started at start
+func (p *loadProfile) factor(start, now time.Time) float64 {
+	elapsed := now.Sub(start)
+	factor := 1.0
+
+	if r := p.cfg.Ramp; r != nil {
+		progress := min(1, float64(elapsed)/float64(r.Duration))
+		factor *= r.From + (r.To-r.From)*progress
+	}
+
+	if s := p.cfg.Sine; s != nil {
+		factor *= 1 + s.Amplitude*math.Sin(2*math.Pi*float64(elapsed)/float64(s.Period))
+	}
+
+	if p.daily != nil {
+		// Interpolate between the weights of the current and the next hour
+		now := now.In(p.loc)
+		midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, p.loc)
+		hours := now.Sub(midnight).Hours()
+		hour := int(hours) % 24
+		frac := hours - math.Floor(hours)
+		factor *= p.daily[hour]*(1-frac) + p.daily[(hour+1)%24]*frac
+	}
+
+	for _, b := range p.cfg.Bursts {
+		if elapsed >= b.Offset && (elapsed-b.Offset)%b.Every < b.Duration {
+			factor *= b.Multiplier
+		}
+	}
+
+	return factor
+}
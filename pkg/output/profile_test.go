@@ -0,0 +1,99 @@
+package output
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/P1llus/genlog/pkg/config"
+)
+
+func TestLoadProfileFactor(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	daily := make([]float64, 24)
+	for i := range daily {
+		daily[i] = 1
+	}
+	daily[12] = 25 // the average of all weights is 2
+
+	tests := []struct {
+		name    string
+		profile config.LoadProfile
+		loc     *time.Location
+		elapsed time.Duration
+		want    float64
+	}{
+		{
+			name:    "ramp halfway",
+			profile: config.LoadProfile{Ramp: &config.RampProfile{From: 1, To: 3, Duration: time.Minute}},
+			elapsed: 30 * time.Second,
+			want:    2,
+		},
+		{
+			name:    "ramp finished",
+			profile: config.LoadProfile{Ramp: &config.RampProfile{From: 1, To: 3, Duration: time.Minute}},
+			elapsed: time.Hour,
+			want:    3,
+		},
+		{
+			name:    "sine peak with default amplitude",
+			profile: config.LoadProfile{Sine: &config.SineProfile{Period: time.Hour}},
+			elapsed: 15 * time.Minute,
+			want:    1.5,
+		},
+		{
+			name:    "daily peak hour",
+			profile: config.LoadProfile{Daily: daily},
+			elapsed: 12 * time.Hour,
+			want:    12.5,
+		},
+		{
+			name:    "daily peak hour in the configured time zone",
+			profile: config.LoadProfile{Daily: daily},
+			loc:     time.FixedZone("UTC+2", 2*60*60),
+			elapsed: 10 * time.Hour,
+			want:    12.5,
+		},
+		{
+			name:    "daily between hours",
+			profile: config.LoadProfile{Daily: daily},
+			elapsed: 11*time.Hour + 30*time.Minute,
+			want:    6.5,
+		},
+		{
+			name: "inside burst",
+			profile: config.LoadProfile{Bursts: []config.BurstProfile{
+				{Multiplier: 10, Duration: 30 * time.Second, Every: 10 * time.Minute, Offset: 5 * time.Minute},
+			}},
+			elapsed: 15*time.Minute + 10*time.Second,
+			want:    10,
+		},
+		{
+			name: "before first burst",
+			profile: config.LoadProfile{Bursts: []config.BurstProfile{
+				{Multiplier: 10, Duration: 30 * time.Second, Every: 10 * time.Minute, Offset: 5 * time.Minute},
+			}},
+			elapsed: 10 * time.Second,
+			want:    1,
+		},
+		{
+			name: "finished ramp with burst",
+			profile: config.LoadProfile{
+				Ramp:   &config.RampProfile{From: 0, To: 1, Duration: time.Minute},
+				Bursts: []config.BurstProfile{{Multiplier: 4, Duration: time.Second, Every: time.Minute}},
+			},
+			elapsed: time.Hour + 500*time.Millisecond,
+			want:    4,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := newLoadProfile(tt.profile, tt.loc).factor(start, start.Add(tt.elapsed))
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("factor() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
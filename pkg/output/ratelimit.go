@@ -0,0 +1,89 @@
+package output
+
+import (
+	"sync"
+	"time"
+
+	"github.com/P1llus/genlog/pkg/config"
+)
+
+// maxReserveWait is the longest wait Reserve hands out. Longer waits are
+// retried instead, so a rate that rises with the load profile is picked up.
+const maxReserveWait = 100 * time.Millisecond
+
+// RateLimiter is a token bucket limiting events per second.
+// A single limiter can be shared by several workers to limit their combined rate.
+type RateLimiter struct {
+	rate    float64 // tokens added per second before applying the profile
+	burst   float64 // maximum number of tokens in the bucket
+	tokens  float64 // may go negative when waits have been handed out
+	profile *loadProfile
+	start   time.Time // first reservation, the start of the load profile
+	last    time.Time
+	now     func() time.Time
+	mu      sync.Mutex
+}
+
+// NewRateLimiter creates a limiter allowing rate events per second with bursts
+// of up to burst events. A burst of 0 defaults to a tenth of a second's worth
+// of events, at least one. When profile is not nil the rate follows it over
+// time, starting from the first reservation, with its daily hours in loc.
+func NewRateLimiter(rate float64, burst int, profile *config.LoadProfile, loc *time.Location) *RateLimiter {
+	if burst <= 0 {
+		burst = max(1, int(rate/10))
+	}
+	l := &RateLimiter{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		now:    time.Now,
+	}
+	if profile != nil {
+		l.profile = newLoadProfile(*profile, loc)
+	}
+	return l
+}
+
+// currentRate returns the rate at now, following the load profile
+func (l *RateLimiter) currentRate(now time.Time) float64 {
+	if l.profile == nil {
+		return l.rate
+	}
+	return l.rate * l.profile.factor(l.start, now)
+}
+
+// Reserve takes a token for one event and returns how long the caller has to
+// wait before sending it. Reservations are handed out in order, so callers
+// sharing the limiter never exceed the rate together. When the next token is
+// too far away, for example because the load profile brings the rate close to
+// zero, no token is taken, ok is false and the caller should wait and try again.
+func (l *RateLimiter) Reserve() (wait time.Duration, ok bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if l.start.IsZero() {
+		l.start = now
+		l.last = now
+	}
+
+	rate := l.currentRate(now)
+	if elapsed := now.Sub(l.last); elapsed > 0 {
+		l.tokens = min(l.burst, l.tokens+elapsed.Seconds()*rate)
+		l.last = now
+	}
+
+	if l.tokens >= 1 {
+		l.tokens--
+		return 0, true
+	}
+	if rate <= 0 {
+		return maxReserveWait, false
+	}
+	wait = time.Duration((1 - l.tokens) / rate * float64(time.Second))
+	if wait > maxReserveWait {
+		return maxReserveWait, false
+	}
+	l.tokens--
+	return wait, true
+}
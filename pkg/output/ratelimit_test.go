@@ -0,0 +1,125 @@
+package output
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/P1llus/genlog/pkg/config"
+)
+
+// reservation is the result of a single RateLimiter.Reserve call
+type reservation struct {
+	wait time.Duration
+	ok   bool
+}
+
+func TestRateLimiterReserve(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	limiter := NewRateLimiter(10, 2, nil, nil)
+	limiter.now = func() time.Time { return now }
+
+	// The burst is available straight away, after that every event waits 100ms
+	// more until the wait gets too long and has to be retried
+	want := []reservation{{0, true}, {0, true}, {100 * time.Millisecond, true}, {100 * time.Millisecond, false}}
+	for i, w := range want {
+		if wait, ok := limiter.Reserve(); wait != w.wait || ok != w.ok {
+			t.Errorf("Reserve() #%d = %v, %v, want %v, %v", i+1, wait, ok, w.wait, w.ok)
+		}
+	}
+
+	// Idle time refills the bucket, but never beyond the burst size
+	now = now.Add(10 * time.Second)
+	for i := 0; i < 2; i++ {
+		if wait, ok := limiter.Reserve(); wait != 0 || !ok {
+			t.Errorf("Reserve() after idle = %v, %v, want 0, true", wait, ok)
+		}
+	}
+	if wait, _ := limiter.Reserve(); wait != 100*time.Millisecond {
+		t.Errorf("Reserve() beyond burst = %v, want 100ms", wait)
+	}
+}
+
+func TestRateLimiterLoadProfile(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := start
+	limiter := NewRateLimiter(10, 1, &config.LoadProfile{
+		Ramp: &config.RampProfile{From: 0, To: 1, Duration: 10 * time.Second},
+	}, time.UTC)
+	limiter.now = func() time.Time { return now }
+
+	// The initial burst is available, but at a rate of zero nothing more is handed out
+	if _, ok := limiter.Reserve(); !ok {
+		t.Fatal("Expected the initial burst to be available")
+	}
+	if wait, ok := limiter.Reserve(); ok || wait != maxReserveWait {
+		t.Errorf("Reserve() at rate 0 = %v, %v, want %v, false", wait, ok, maxReserveWait)
+	}
+
+	// Halfway through the ramp the rate is 5 EPS, refilling one token in 200ms
+	now = start.Add(5 * time.Second)
+	limiter.Reserve()
+	now = now.Add(200 * time.Millisecond)
+	if wait, ok := limiter.Reserve(); !ok || wait > time.Millisecond {
+		t.Errorf("Reserve() at 5 EPS = %v, %v, want about 0, true", wait, ok)
+	}
+}
+
+func TestRateLimiterDefaultBurst(t *testing.T) {
+	tests := []struct {
+		rate float64
+		want float64
+	}{
+		{rate: 1, want: 1},
+		{rate: 500, want: 50},
+	}
+
+	for _, tt := range tests {
+		if got := NewRateLimiter(tt.rate, 0, nil, nil).burst; got != tt.want {
+			t.Errorf("NewRateLimiter(%v, 0).burst = %v, want %v", tt.rate, got, tt.want)
+		}
+	}
+}
+
+// countingOutput counts the messages written to it
+type countingOutput struct {
+	count int
+	mu    sync.Mutex
+}
+
+func (o *countingOutput) Write(messages []string) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.count += len(messages)
+	return nil
+}
+
+func (o *countingOutput) Close() error {
+	return nil
+}
+
+func TestWorkerSharedRateLimit(t *testing.T) {
+	out := &countingOutput{}
+	limiter := NewRateLimiter(100, 1, nil, nil)
+	stopChan := make(chan struct{})
+
+	// Two workers sharing one limiter should produce about 100 events per second together
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		worker := NewWorker(out, &mockGenerator{lines: []string{"test message"}}, 10, 0, stopChan)
+		worker.SetRateLimiters(limiter)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			worker.Start()
+		}()
+	}
+
+	time.Sleep(500 * time.Millisecond)
+	close(stopChan)
+	wg.Wait()
+
+	if out.count < 35 || out.count > 60 {
+		t.Errorf("Expected about 50 events in 500ms at 100 EPS, got %d", out.count)
+	}
+}
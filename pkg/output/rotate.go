@@ -0,0 +1,161 @@
+package output
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/P1llus/genlog/pkg/config"
+)
+
+// shouldRotate reports whether the file has to be rotated before writing n more bytes
+func (o *fileOutput) shouldRotate(n int64) bool {
+	if o.cfg.MaxSize > 0 && o.size > 0 && o.size+n > int64(o.cfg.MaxSize) {
+		return true
+	}
+	return o.cfg.MaxAge > 0 && time.Since(o.openedAt) >= o.cfg.MaxAge
+}
+
+// rotate moves the current contents to <filename>.1, shifting older backups,
+// and continues writing to an empty file
+func (o *fileOutput) rotate() error {
+	if err := o.writer.Flush(); err != nil {
+		return err
+	}
+	if err := o.shiftBackups(); err != nil {
+		return err
+	}
+
+	// Compressed outputs keep their extension, e.g. app.log.gz becomes app.log.1.gz
+	ext := o.cfg.Compression.Extension()
+	backup := o.backupName(1, ext)
+	if o.cfg.RotateMode == config.RotateModeCopyTruncate {
+		if err := o.copyTruncate(backup); err != nil {
+			return err
+		}
+	} else {
+		if err := o.closeFile(); err != nil {
+			return err
+		}
+		if err := os.Rename(o.filename, backup); err != nil {
+			return err
+		}
+		if err := o.open(); err != nil {
+			return err
+		}
+	}
+
+	if o.cfg.CompressBackups && ext == "" {
+		return compressFile(backup)
+	}
+	return nil
+}
+
+// copyTruncate copies the current file to backup and truncates it in place,
+// so the output keeps writing to the same inode
+func (o *fileOutput) copyTruncate(backup string) error {
+	dst, err := os.Create(backup)
+	if err != nil {
+		return err
+	}
+	if _, err := o.file.Seek(0, io.SeekStart); err != nil {
+		dst.Close()
+		return err
+	}
+	if _, err := io.Copy(dst, o.file); err != nil {
+		dst.Close()
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		return err
+	}
+
+	if err := o.file.Truncate(0); err != nil {
+		return err
+	}
+	if _, err := o.file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	o.size = 0
+	o.openedAt = time.Now()
+	return nil
+}
+
+// backupExtensions are the extensions a rotated file can have
+var backupExtensions = []string{"", ".gz", ".zst"}
+
+// backupName returns the name of the nth rotated file with the given compression extension
+func (o *fileOutput) backupName(n int, ext string) string {
+	return o.base + "." + strconv.Itoa(n) + ext
+}
+
+// existingBackup returns the name and extension of the nth rotated file if
+// it exists, whether it was compressed or not
+func (o *fileOutput) existingBackup(n int) (string, string, bool) {
+	for _, ext := range backupExtensions {
+		name := o.backupName(n, ext)
+		if _, err := os.Stat(name); err == nil {
+			return name, ext, true
+		}
+	}
+	return "", "", false
+}
+
+// shiftBackups renames <filename>.n to <filename>.n+1 to make room for a new
+// backup, removing backups beyond MaxBackups
+func (o *fileOutput) shiftBackups() error {
+	// Find the oldest backup that has to be kept or moved
+	last := 0
+	for {
+		if _, _, ok := o.existingBackup(last + 1); !ok {
+			break
+		}
+		last++
+	}
+
+	for n := last; n >= 1; n-- {
+		name, ext, _ := o.existingBackup(n)
+		if o.cfg.MaxBackups > 0 && n >= o.cfg.MaxBackups {
+			if err := os.Remove(name); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := os.Rename(name, o.backupName(n+1, ext)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// compressFile gzips the file at path to path.gz and removes the original
+func compressFile(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+
+	dst, err := os.Create(path + ".gz")
+	if err != nil {
+		src.Close()
+		return err
+	}
+
+	gz := gzip.NewWriter(dst)
+	_, err = io.Copy(gz, src)
+	if closeErr := gz.Close(); err == nil {
+		err = closeErr
+	}
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	src.Close()
+	if err != nil {
+		return fmt.Errorf("error compressing %s: %w", path, err)
+	}
+	return os.Remove(path)
+}
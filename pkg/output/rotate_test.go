@@ -0,0 +1,261 @@
+package output
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/P1llus/genlog/pkg/config"
+	"github.com/klauspost/compress/zstd"
+)
+
+// readFile returns the contents of a file, failing the test if it can't be read
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read %s: %v", path, err)
+	}
+	return string(data)
+}
+
+// readCompressed returns the decompressed contents of a .gz or .zst file
+func readCompressed(t *testing.T, path string) string {
+	t.Helper()
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open %s: %v", path, err)
+	}
+	defer file.Close()
+
+	var r io.Reader
+	switch filepath.Ext(path) {
+	case ".gz":
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			t.Fatalf("Invalid gzip file %s: %v", path, err)
+		}
+		r = gz
+	case ".zst":
+		decoder, err := zstd.NewReader(file)
+		if err != nil {
+			t.Fatalf("Invalid zstd file %s: %v", path, err)
+		}
+		defer decoder.Close()
+		r = decoder
+	default:
+		t.Fatalf("Unknown compression for %s", path)
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("Failed to decompress %s: %v", path, err)
+	}
+	return string(data)
+}
+
+func TestFileOutputSizeRotation(t *testing.T) {
+	// Create a temporary directory for test files
+	tmpDir, err := os.MkdirTemp("", "rotate-test-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	filename := filepath.Join(tmpDir, "rotate.log")
+
+	cfg := config.OutputConfig{
+		Type:    config.OutputTypeFile,
+		Workers: 1,
+		Config: map[string]interface{}{
+			"filename":    filename,
+			"max_size":    "20B",
+			"max_backups": 2,
+		},
+	}
+
+	out, err := NewOutput(cfg, 0)
+	if err != nil {
+		t.Fatalf("NewOutput failed: %v", err)
+	}
+
+	// Every message is 10 bytes including the newline, so each file holds two
+	for _, msg := range []string{"message 1", "message 2", "message 3", "message 4", "message 5", "message 6", "message 7"} {
+		if err := out.Write([]string{msg}); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+	if err := out.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	want := map[string]string{
+		filename:        "message 7\n",
+		filename + ".1": "message 5\nmessage 6\n",
+		filename + ".2": "message 3\nmessage 4\n",
+	}
+	for path, content := range want {
+		if got := readFile(t, path); got != content {
+			t.Errorf("%s = %q, want %q", filepath.Base(path), got, content)
+		}
+	}
+	if _, err := os.Stat(filename + ".3"); !os.IsNotExist(err) {
+		t.Errorf("Expected backups beyond max_backups to be removed")
+	}
+}
+
+func TestFileOutputCopyTruncate(t *testing.T) {
+	// Create a temporary directory for test files
+	tmpDir, err := os.MkdirTemp("", "rotate-test-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	filename := filepath.Join(tmpDir, "copytruncate.log")
+
+	cfg := config.OutputConfig{
+		Type:    config.OutputTypeFile,
+		Workers: 1,
+		Config: map[string]interface{}{
+			"filename":    filename,
+			"max_size":    15,
+			"rotate_mode": "copytruncate",
+		},
+	}
+
+	out, err := NewOutput(cfg, 0)
+	if err != nil {
+		t.Fatalf("NewOutput failed: %v", err)
+	}
+	defer out.Close()
+
+	before, err := os.Stat(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := out.Write([]string{"message 1", "message 2"}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	after, err := os.Stat(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !os.SameFile(before, after) {
+		t.Error("Expected copytruncate to keep writing to the same file")
+	}
+	if got := readFile(t, filename); got != "message 2\n" {
+		t.Errorf("Current file = %q, want %q", got, "message 2\n")
+	}
+	if got := readFile(t, filename+".1"); got != "message 1\n" {
+		t.Errorf("Backup file = %q, want %q", got, "message 1\n")
+	}
+}
+
+func TestFileOutputAgeRotationWithCompression(t *testing.T) {
+	// Create a temporary directory for test files
+	tmpDir, err := os.MkdirTemp("", "rotate-test-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	filename := filepath.Join(tmpDir, "age.log")
+
+	cfg := config.OutputConfig{
+		Type:    config.OutputTypeFile,
+		Workers: 1,
+		Config: map[string]interface{}{
+			"filename":         filename,
+			"max_age":          "50ms",
+			"compress_backups": true,
+		},
+	}
+
+	out, err := NewOutput(cfg, 0)
+	if err != nil {
+		t.Fatalf("NewOutput failed: %v", err)
+	}
+	defer out.Close()
+
+	if err := out.Write([]string{"old message"}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	time.Sleep(60 * time.Millisecond)
+	if err := out.Write([]string{"new message"}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	if got := readFile(t, filename); got != "new message\n" {
+		t.Errorf("Current file = %q, want %q", got, "new message\n")
+	}
+	if _, err := os.Stat(filename + ".1"); !os.IsNotExist(err) {
+		t.Error("Expected uncompressed backup to be removed")
+	}
+
+	file, err := os.Open(filename + ".1.gz")
+	if err != nil {
+		t.Fatalf("Expected compressed backup: %v", err)
+	}
+	defer file.Close()
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		t.Fatalf("Invalid gzip backup: %v", err)
+	}
+	data, err := io.ReadAll(gz)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "old message") {
+		t.Errorf("Compressed backup = %q, want old message", data)
+	}
+}
+
+func TestFileOutputCompressedRotation(t *testing.T) {
+	// Create a temporary directory for test files
+	tmpDir, err := os.MkdirTemp("", "rotate-test-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	filename := filepath.Join(tmpDir, "app.log.zst")
+
+	cfg := config.OutputConfig{
+		Type:    config.OutputTypeFile,
+		Workers: 1,
+		Config: map[string]interface{}{
+			"filename": filename,
+			"max_size": 20,
+		},
+	}
+
+	out, err := NewOutput(cfg, 0)
+	if err != nil {
+		t.Fatalf("NewOutput failed: %v", err)
+	}
+
+	// max_size counts uncompressed bytes, so each file holds two messages
+	for _, msg := range []string{"message 1", "message 2", "message 3"} {
+		if err := out.Write([]string{msg}); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+	if err := out.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	if got := readCompressed(t, filename); got != "message 3\n" {
+		t.Errorf("Current file = %q, want %q", got, "message 3\n")
+	}
+	backup := filepath.Join(tmpDir, "app.log.1.zst")
+	if got := readCompressed(t, backup); got != "message 1\nmessage 2\n" {
+		t.Errorf("Backup file = %q, want %q", got, "message 1\nmessage 2\n")
+	}
+}
@@ -0,0 +1,238 @@
+package output
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/P1llus/genlog/pkg/config"
+)
+
+// syslogOutput implements Output by wrapping every message in a syslog
+// envelope before sending it over a UDP or TCP transport
+type syslogOutput struct {
+	transport Output
+	cfg       config.SyslogOutputConfig
+	facility  int
+	severity  int
+	lines     []string
+	now       func() time.Time
+	mu        sync.Mutex // Protects lines during concurrent writes
+}
+
+func newSyslogOutput(cfg config.OutputConfig) (*syslogOutput, error) {
+	var sysCfg config.SyslogOutputConfig
+	if err := cfg.Decode(&sysCfg); err != nil {
+		return nil, err
+	}
+	if err := sysCfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	o := &syslogOutput{cfg: sysCfg, now: time.Now}
+	o.applyDefaults()
+
+	switch o.cfg.Network {
+	case "udp":
+		transport, err := dialUDP(o.cfg.Address)
+		if err != nil {
+			return nil, err
+		}
+		o.transport = transport
+	case "tcp":
+		transport, err := dialTCP(o.cfg.TCPOutputConfig)
+		if err != nil {
+			return nil, err
+		}
+		o.transport = transport
+	default:
+		return nil, fmt.Errorf("unsupported syslog network: %s", o.cfg.Network)
+	}
+
+	return o, nil
+}
+
+// applyDefaults fills in unset header fields of a validated configuration
+// and resolves the default facility and severity codes
+func (o *syslogOutput) applyDefaults() {
+	if o.cfg.Network == "" {
+		o.cfg.Network = "udp"
+	}
+	if o.cfg.Format == "" {
+		o.cfg.Format = config.SyslogFormatRFC5424
+	}
+	if o.cfg.Hostname == "" {
+		o.cfg.Hostname, _ = os.Hostname()
+	}
+	if o.cfg.AppName == "" {
+		o.cfg.AppName = "genlog"
+	}
+
+	if o.cfg.Facility == "" {
+		o.cfg.Facility = "user"
+	}
+	o.facility, _ = config.SyslogFacility(o.cfg.Facility)
+
+	if o.cfg.Severity == "" {
+		o.cfg.Severity = "info"
+	}
+	o.severity, _ = config.SyslogSeverity(o.cfg.Severity)
+
+	// Normalize the severity map so lookups can ignore case
+	severityMap := make(map[string]string, len(o.cfg.SeverityMap))
+	for from, to := range o.cfg.SeverityMap {
+		severityMap[strings.ToLower(from)] = to
+	}
+	o.cfg.SeverityMap = severityMap
+}
+
+// priority works out the PRI value of an event from its fields, falling
+// back to the configured facility and severity
+func (o *syslogOutput) priority(event Event) int {
+	facility := o.facility
+	if value, ok := event.Values[o.cfg.FacilityField]; ok {
+		if code, ok := config.SyslogFacility(value); ok {
+			facility = code
+		}
+	}
+
+	severity := o.severity
+	if value, ok := event.Values[o.cfg.SeverityField]; ok {
+		if mapped, ok := o.cfg.SeverityMap[strings.ToLower(value)]; ok {
+			value = mapped
+		}
+		if code, ok := config.SyslogSeverity(value); ok {
+			severity = code
+		}
+	}
+
+	return facility*8 + severity
+}
+
+// format wraps a single event in a syslog envelope
+func (o *syslogOutput) format(event Event) string {
+	var b strings.Builder
+	b.WriteByte('<')
+	b.WriteString(strconv.Itoa(o.priority(event)))
+	b.WriteByte('>')
+
+	// The envelope carries the time of the line, which a simulated or
+	// backfill clock may have set in the past
+	now := event.Time
+	if now.IsZero() {
+		now = o.now()
+	}
+	if o.cfg.Format == config.SyslogFormatRFC3164 {
+		b.WriteString(now.Format(time.Stamp))
+		b.WriteByte(' ')
+		b.WriteString(headerValue(o.cfg.Hostname))
+		b.WriteByte(' ')
+		b.WriteString(o.cfg.AppName)
+		if o.cfg.ProcID != "" && o.cfg.ProcID != "-" {
+			b.WriteByte('[')
+			b.WriteString(o.cfg.ProcID)
+			b.WriteByte(']')
+		}
+		b.WriteString(": ")
+		b.WriteString(event.Line)
+		return b.String()
+	}
+
+	b.WriteString("1 ")
+	b.WriteString(now.Format("2006-01-02T15:04:05.000000Z07:00"))
+	for _, field := range []string{o.cfg.Hostname, o.cfg.AppName, o.cfg.ProcID, o.cfg.MsgID} {
+		b.WriteByte(' ')
+		b.WriteString(headerValue(field))
+	}
+	b.WriteByte(' ')
+	o.writeStructuredData(&b, event)
+	b.WriteByte(' ')
+	b.WriteString(event.Line)
+	return b.String()
+}
+
+// writeStructuredData writes the RFC 5424 STRUCTURED-DATA part of a message
+func (o *syslogOutput) writeStructuredData(b *strings.Builder, event Event) {
+	if o.cfg.StructuredDataID == "" || len(event.Values) == 0 {
+		b.WriteByte('-')
+		return
+	}
+
+	names := make([]string, 0, len(event.Values))
+	for name := range event.Values {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	b.WriteByte('[')
+	b.WriteString(sdName(o.cfg.StructuredDataID))
+	for _, name := range names {
+		b.WriteByte(' ')
+		b.WriteString(sdName(name))
+		b.WriteString(`="`)
+		b.WriteString(sdParamEscaper.Replace(event.Values[name]))
+		b.WriteByte('"')
+	}
+	b.WriteByte(']')
+}
+
+// sdParamEscaper escapes the characters RFC 5424 requires to be escaped in PARAM-VALUE
+var sdParamEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`)
+
+// sdName replaces characters that are not allowed in an SD-NAME and
+// truncates it to the maximum length of 32 characters
+func sdName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if r <= ' ' || r > '~' || r == '=' || r == ']' || r == '"' {
+			return '_'
+		}
+		return r
+	}, name)
+	if len(name) > 32 {
+		name = name[:32]
+	}
+	return name
+}
+
+// headerValue returns the RFC 5424 nil value for empty header fields
+func headerValue(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
+func (o *syslogOutput) Write(messages []string) error {
+	events := make([]Event, len(messages))
+	for i, msg := range messages {
+		events[i] = Event{Line: msg}
+	}
+	return o.WriteEvents(events)
+}
+
+// WriteEvents wraps each event in a syslog envelope and sends the batch over the transport
+func (o *syslogOutput) WriteEvents(events []Event) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.lines = o.lines[:0]
+	for _, event := range events {
+		o.lines = append(o.lines, o.format(event))
+	}
+	return o.transport.Write(o.lines)
+}
+
+// Cancel stops a TCP transport from reconnecting, see tcpOutput.Cancel
+func (o *syslogOutput) Cancel() {
+	if transport, ok := o.transport.(Canceler); ok {
+		transport.Cancel()
+	}
+}
+
+func (o *syslogOutput) Close() error {
+	return o.transport.Close()
+}
@@ -0,0 +1,231 @@
+package output
+
+import (
+	"net"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/P1llus/genlog/pkg/config"
+)
+
+// listenUDP starts a UDP server for syslog tests
+func listenUDP(t *testing.T) *net.UDPConn {
+	t.Helper()
+	addr, err := net.ResolveUDPAddr("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, err := net.ListenUDP("udp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return conn
+}
+
+// readUDPMessages reads n datagrams from the connection, without the trailing newline
+func readUDPMessages(t *testing.T, conn *net.UDPConn, n int) []string {
+	t.Helper()
+	buf := make([]byte, 2048)
+	messages := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		conn.SetReadDeadline(time.Now().Add(1 * time.Second))
+		size, _, err := conn.ReadFromUDP(buf)
+		if err != nil {
+			t.Fatalf("Failed to read message %d: %v", i, err)
+		}
+		messages = append(messages, strings.TrimSuffix(string(buf[:size]), "\n"))
+	}
+	return messages
+}
+
+func TestSyslogOutputRFC5424(t *testing.T) {
+	conn := listenUDP(t)
+	defer conn.Close()
+
+	cfg := config.OutputConfig{
+		Type:    config.OutputTypeSyslog,
+		Workers: 1,
+		Config: map[string]interface{}{
+			"address":            conn.LocalAddr().String(),
+			"hostname":           "testhost",
+			"app_name":           "testapp",
+			"procid":             "42",
+			"msgid":              "AUTH",
+			"facility":           "local0",
+			"severity_field":     "level",
+			"structured_data_id": "genlog@32473",
+			"severity_map": map[string]interface{}{
+				"VERBOSE": "debug",
+			},
+		},
+	}
+
+	out, err := NewOutput(cfg, 0)
+	if err != nil {
+		t.Fatalf("NewOutput failed: %v", err)
+	}
+	defer out.Close()
+
+	events := []Event{
+		{Line: "user logged in", Values: map[string]string{"level": "ERROR", "user": `al"ice`}},
+		{Line: "cache warmed", Values: map[string]string{"level": "VERBOSE"}},
+		{Line: "no level"},
+	}
+	if err := out.(EventOutput).WriteEvents(events); err != nil {
+		t.Fatalf("WriteEvents failed: %v", err)
+	}
+
+	header := `\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}\.\d{6}(Z|[+-]\d{2}:\d{2}) testhost testapp 42 AUTH `
+	patterns := []string{
+		// local0 (16) * 8 + err (3)
+		`^<131>1 ` + header + `\[genlog@32473 level="ERROR" user="al\\"ice"\] user logged in$`,
+		// local0 (16) * 8 + debug (7) through the severity map
+		`^<135>1 ` + header + `\[genlog@32473 level="VERBOSE"\] cache warmed$`,
+		// local0 (16) * 8 + default severity info (6)
+		`^<134>1 ` + header + `- no level$`,
+	}
+
+	received := readUDPMessages(t, conn, len(patterns))
+	for i, pattern := range patterns {
+		if !regexp.MustCompile(pattern).MatchString(received[i]) {
+			t.Errorf("Message %d doesn't match %s\ngot: %s", i, pattern, received[i])
+		}
+	}
+}
+
+func TestSyslogOutputRFC3164(t *testing.T) {
+	conn := listenUDP(t)
+	defer conn.Close()
+
+	cfg := config.OutputConfig{
+		Type:    config.OutputTypeSyslog,
+		Workers: 1,
+		Config: map[string]interface{}{
+			"address":        conn.LocalAddr().String(),
+			"format":         "rfc3164",
+			"hostname":       "testhost",
+			"app_name":       "sshd",
+			"procid":         "1234",
+			"facility":       "auth",
+			"severity":       "notice",
+			"severity_field": "level",
+		},
+	}
+
+	out, err := NewOutput(cfg, 0)
+	if err != nil {
+		t.Fatalf("NewOutput failed: %v", err)
+	}
+	defer out.Close()
+
+	if err := out.Write([]string{"Accepted password for root"}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	received := readUDPMessages(t, conn, 1)[0]
+	// auth (4) * 8 + notice (5)
+	pattern := `^<37>[A-Z][a-z]{2} [ \d]\d \d{2}:\d{2}:\d{2} testhost sshd\[1234\]: Accepted password for root$`
+	if !regexp.MustCompile(pattern).MatchString(received) {
+		t.Errorf("Message doesn't match %s\ngot: %s", pattern, received)
+	}
+}
+
+func TestSyslogOutputEventTime(t *testing.T) {
+	conn := listenUDP(t)
+	defer conn.Close()
+
+	cfg := config.OutputConfig{
+		Type:    config.OutputTypeSyslog,
+		Workers: 1,
+		Config: map[string]interface{}{
+			"address":  conn.LocalAddr().String(),
+			"hostname": "testhost",
+		},
+	}
+
+	out, err := NewOutput(cfg, 0)
+	if err != nil {
+		t.Fatalf("NewOutput failed: %v", err)
+	}
+	defer out.Close()
+
+	// A backfill clock sets the time of the line in the past
+	lineTime := time.Date(2024, 1, 2, 3, 4, 5, 6000, time.UTC)
+	if err := out.(EventOutput).WriteEvents([]Event{{Line: "backfilled", Time: lineTime}}); err != nil {
+		t.Fatalf("WriteEvents failed: %v", err)
+	}
+
+	want := "<14>1 2024-01-02T03:04:05.000006Z testhost genlog - - - backfilled"
+	if received := readUDPMessages(t, conn, 1)[0]; received != want {
+		t.Errorf("Message = %q, want %q", received, want)
+	}
+}
+
+func TestSyslogOutputTCP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	cfg := config.OutputConfig{
+		Type:    config.OutputTypeSyslog,
+		Workers: 1,
+		Config: map[string]interface{}{
+			"address":  ln.Addr().String(),
+			"network":  "tcp",
+			"hostname": "testhost",
+		},
+	}
+
+	out, err := NewOutput(cfg, 0)
+	if err != nil {
+		t.Fatalf("NewOutput failed: %v", err)
+	}
+	defer out.Close()
+
+	if err := out.Write([]string{"over tcp"}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	received := acceptLines(t, ln, 1)[0]
+	// user (1) * 8 + info (6)
+	if !strings.HasPrefix(received, "<14>1 ") || !strings.HasSuffix(received, " testhost genlog - - - over tcp") {
+		t.Errorf("Unexpected syslog message: %s", received)
+	}
+}
+
+func TestSyslogOutputInvalidConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		config map[string]interface{}
+	}{
+		{
+			name:   "missing address",
+			config: map[string]interface{}{},
+		},
+		{
+			name:   "unknown facility",
+			config: map[string]interface{}{"address": "127.0.0.1:514", "facility": "local9"},
+		},
+		{
+			name:   "unknown format",
+			config: map[string]interface{}{"address": "127.0.0.1:514", "format": "rfc9999"},
+		},
+		{
+			name:   "unknown network",
+			config: map[string]interface{}{"address": "127.0.0.1:514", "network": "sctp"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.OutputConfig{Type: config.OutputTypeSyslog, Workers: 1, Config: tt.config}
+			if _, err := NewOutput(cfg, 0); err == nil {
+				t.Error("Expected error for invalid syslog config, got nil")
+			}
+		})
+	}
+}
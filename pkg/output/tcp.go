@@ -0,0 +1,266 @@
+package output
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/P1llus/genlog/pkg/config"
+)
+
+const (
+	defaultDialTimeout       = 5 * time.Second
+	defaultReconnectDelay    = 500 * time.Millisecond
+	defaultMaxReconnectDelay = 30 * time.Second
+)
+
+// tcpOutput implements Output for TCP destinations.
+// When the connection drops it reconnects with exponential backoff
+// and resends the batch that failed.
+type tcpOutput struct {
+	cfg       config.TCPOutputConfig
+	tlsConfig *tls.Config
+	conn      net.Conn
+	dropped   chan struct{} // closed once conn is no longer usable
+	buf       bytes.Buffer
+	ends      []int         // end offset of every frame in buf
+	closed    chan struct{} // closed by Cancel or Close to stop reconnecting
+	closeOnce sync.Once
+	mu        sync.Mutex
+}
+
+func newTCPOutput(cfg config.OutputConfig) (*tcpOutput, error) {
+	var tcpCfg config.TCPOutputConfig
+	if err := cfg.Decode(&tcpCfg); err != nil {
+		return nil, err
+	}
+	if err := tcpCfg.Validate(); err != nil {
+		return nil, err
+	}
+	return dialTCP(tcpCfg)
+}
+
+// dialTCP applies defaults to a validated TCP configuration and opens the
+// initial connection
+func dialTCP(tcpCfg config.TCPOutputConfig) (*tcpOutput, error) {
+	if tcpCfg.Framing == "" {
+		tcpCfg.Framing = config.TCPFramingNewline
+	}
+	if tcpCfg.DialTimeout <= 0 {
+		tcpCfg.DialTimeout = defaultDialTimeout
+	}
+	if tcpCfg.ReconnectDelay <= 0 {
+		tcpCfg.ReconnectDelay = defaultReconnectDelay
+	}
+	if tcpCfg.MaxReconnectDelay < tcpCfg.ReconnectDelay {
+		tcpCfg.MaxReconnectDelay = max(defaultMaxReconnectDelay, tcpCfg.ReconnectDelay)
+	}
+
+	o := &tcpOutput{
+		cfg:    tcpCfg,
+		closed: make(chan struct{}),
+	}
+
+	if tcpCfg.TLS != nil {
+		tlsConfig, err := newTLSConfig(tcpCfg.TLS, tcpCfg.Address)
+		if err != nil {
+			return nil, err
+		}
+		o.tlsConfig = tlsConfig
+	}
+
+	if err := o.connect(); err != nil {
+		return nil, fmt.Errorf("error creating TCP connection: %w", err)
+	}
+	return o, nil
+}
+
+// newTLSConfig builds a client TLS configuration from the output settings
+func newTLSConfig(cfg *config.TLSConfig, address string) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		ServerName:         cfg.ServerName,
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+	}
+	if tlsConfig.ServerName == "" {
+		if host, _, err := net.SplitHostPort(address); err == nil {
+			tlsConfig.ServerName = host
+		}
+	}
+
+	if cfg.CAFile != "" {
+		pem, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("error reading CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA file %s", cfg.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if cfg.CertFile != "" || cfg.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("error loading client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+// connect dials the configured address, replacing any existing connection
+func (o *tcpOutput) connect() error {
+	if o.conn != nil {
+		o.conn.Close()
+		o.conn = nil
+	}
+
+	dialer := &net.Dialer{Timeout: o.cfg.DialTimeout}
+	var (
+		conn net.Conn
+		err  error
+	)
+	if o.tlsConfig != nil {
+		conn, err = tls.DialWithDialer(dialer, "tcp", o.cfg.Address, o.tlsConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", o.cfg.Address)
+	}
+	if err != nil {
+		return err
+	}
+	o.conn = conn
+	o.dropped = make(chan struct{})
+	go watch(conn, o.dropped)
+	return nil
+}
+
+// reconnect keeps trying to connect with exponential backoff until it succeeds,
+// the retry limit is reached or the output is canceled or closed
+func (o *tcpOutput) reconnect() error {
+	delay := o.cfg.ReconnectDelay
+	for attempt := 1; ; attempt++ {
+		select {
+		case <-o.closed:
+			return fmt.Errorf("output stopped while reconnecting")
+		case <-time.After(delay):
+		}
+
+		err := o.connect()
+		if err == nil {
+			return nil
+		}
+		if o.cfg.MaxRetries > 0 && attempt >= o.cfg.MaxRetries {
+			return fmt.Errorf("giving up after %d reconnect attempts: %w", attempt, err)
+		}
+		delay = min(delay*2, o.cfg.MaxReconnectDelay)
+	}
+}
+
+// watch drains the connection until the remote end closes it and then
+// closes dropped. Log receivers never send data back, so reading is the only
+// reliable way to notice a collector restart before the next batch is lost
+// in the kernel send buffer.
+func watch(conn net.Conn, dropped chan struct{}) {
+	io.Copy(io.Discard, conn)
+	close(dropped)
+}
+
+// connected reports whether there is a connection that has not been dropped
+func (o *tcpOutput) connected() bool {
+	if o.conn == nil {
+		return false
+	}
+	select {
+	case <-o.dropped:
+		return false
+	default:
+		return true
+	}
+}
+
+// frame appends a single message to the buffer using the configured framing
+func (o *tcpOutput) frame(msg string) {
+	if o.cfg.Framing == config.TCPFramingOctetCounting {
+		o.buf.WriteString(strconv.Itoa(len(msg)))
+		o.buf.WriteByte(' ')
+		o.buf.WriteString(msg)
+		return
+	}
+	o.buf.WriteString(msg)
+	o.buf.WriteByte('\n')
+}
+
+// frameStart returns the start of the frame containing offset, the offset
+// of the first byte that has not been written
+func (o *tcpOutput) frameStart(offset int) int {
+	start := 0
+	for _, end := range o.ends {
+		if end > offset {
+			break
+		}
+		start = end
+	}
+	return start
+}
+
+func (o *tcpOutput) Write(messages []string) error {
+	if len(messages) == 0 {
+		return nil
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.buf.Reset()
+	o.ends = o.ends[:0]
+	for _, msg := range messages {
+		o.frame(msg)
+		o.ends = append(o.ends, o.buf.Len())
+	}
+
+	// When a write fails partway, resend from the start of the first frame
+	// that was not fully written, so the new connection never starts in the
+	// middle of a message and complete messages are not sent twice
+	start := 0
+	for {
+		if o.connected() {
+			n, err := o.conn.Write(o.buf.Bytes()[start:])
+			if err == nil {
+				return nil
+			}
+			start = o.frameStart(start + n)
+		}
+		if err := o.reconnect(); err != nil {
+			return fmt.Errorf("error writing TCP batch: %w", err)
+		}
+	}
+}
+
+// Cancel makes a write that is reconnecting, and every later one that loses
+// the connection, give up instead of retrying. Writes over a working
+// connection still succeed, so workers can flush their last batch.
+func (o *tcpOutput) Cancel() {
+	o.closeOnce.Do(func() { close(o.closed) })
+}
+
+func (o *tcpOutput) Close() error {
+	o.Cancel()
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.conn == nil {
+		return nil
+	}
+	err := o.conn.Close()
+	o.conn = nil
+	return err
+}
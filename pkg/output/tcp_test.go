@@ -0,0 +1,363 @@
+package output
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/P1llus/genlog/pkg/config"
+)
+
+// acceptLines accepts a single connection on the listener and returns the
+// first n newline framed messages received on it
+func acceptLines(t *testing.T, ln net.Listener, n int) []string {
+	t.Helper()
+	conn, err := ln.Accept()
+	if err != nil {
+		t.Fatalf("Accept failed: %v", err)
+	}
+	defer conn.Close()
+
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	reader := bufio.NewReader(conn)
+	lines := make([]string, 0, n)
+	for len(lines) < n {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("Failed to read message %d: %v", len(lines), err)
+		}
+		lines = append(lines, strings.TrimSuffix(line, "\n"))
+	}
+	return lines
+}
+
+func TestTCPOutputNewlineFraming(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	cfg := config.OutputConfig{
+		Type:    config.OutputTypeTCP,
+		Workers: 1,
+		Config: map[string]interface{}{
+			"address": ln.Addr().String(),
+		},
+	}
+
+	out, err := NewOutput(cfg, 0)
+	if err != nil {
+		t.Fatalf("NewOutput failed: %v", err)
+	}
+	defer out.Close()
+
+	messages := []string{"test message 1", "test message 2", "test message 3"}
+	if err := out.Write(messages); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	received := acceptLines(t, ln, len(messages))
+	for i, msg := range messages {
+		if received[i] != msg {
+			t.Errorf("Message %d mismatch: got %s, want %s", i, received[i], msg)
+		}
+	}
+}
+
+func TestTCPOutputOctetCounting(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	cfg := config.OutputConfig{
+		Type:    config.OutputTypeTCP,
+		Workers: 1,
+		Config: map[string]interface{}{
+			"address": ln.Addr().String(),
+			"framing": "octet-counting",
+		},
+	}
+
+	out, err := NewOutput(cfg, 0)
+	if err != nil {
+		t.Fatalf("NewOutput failed: %v", err)
+	}
+	defer out.Close()
+
+	messages := []string{"first message", "message with\nembedded newline"}
+	if err := out.Write(messages); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	conn, err := ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	reader := bufio.NewReader(conn)
+
+	for i, msg := range messages {
+		prefix, err := reader.ReadString(' ')
+		if err != nil {
+			t.Fatalf("Failed to read length of message %d: %v", i, err)
+		}
+		length, err := strconv.Atoi(strings.TrimSuffix(prefix, " "))
+		if err != nil {
+			t.Fatalf("Invalid length prefix %q: %v", prefix, err)
+		}
+		body := make([]byte, length)
+		if _, err := io.ReadFull(reader, body); err != nil {
+			t.Fatalf("Failed to read message %d: %v", i, err)
+		}
+		if string(body) != msg {
+			t.Errorf("Message %d mismatch: got %q, want %q", i, body, msg)
+		}
+	}
+}
+
+func TestTCPOutputReconnect(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	cfg := config.OutputConfig{
+		Type:    config.OutputTypeTCP,
+		Workers: 1,
+		Config: map[string]interface{}{
+			"address":         ln.Addr().String(),
+			"reconnect_delay": "10ms",
+		},
+	}
+
+	out, err := NewOutput(cfg, 0)
+	if err != nil {
+		t.Fatalf("NewOutput failed: %v", err)
+	}
+	defer out.Close()
+
+	if err := out.Write([]string{"before restart"}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	// acceptLines closes the server side of the connection once read,
+	// simulating a restart of the receiving collector
+	if got := acceptLines(t, ln, 1)[0]; got != "before restart" {
+		t.Errorf("got %s, want before restart", got)
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- out.Write([]string{"after restart"})
+	}()
+
+	if got := acceptLines(t, ln, 1)[0]; got != "after restart" {
+		t.Errorf("got %s, want after restart", got)
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("Write after reconnect failed: %v", err)
+	}
+}
+
+func TestTCPOutputClosedWhileReconnecting(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.OutputConfig{
+		Type:    config.OutputTypeTCP,
+		Workers: 1,
+		Config: map[string]interface{}{
+			"address":         ln.Addr().String(),
+			"reconnect_delay": "10ms",
+		},
+	}
+
+	out, err := NewOutput(cfg, 0)
+	if err != nil {
+		t.Fatalf("NewOutput failed: %v", err)
+	}
+	conn, err := ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.Close()
+	ln.Close()
+	time.Sleep(50 * time.Millisecond)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- out.Write([]string{"never delivered"})
+	}()
+	time.Sleep(50 * time.Millisecond)
+	out.Close()
+
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Error("Expected error when writing to a closed output, got nil")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Write did not return after Close")
+	}
+}
+
+// partialConn accepts the first n bytes of a write and then fails
+type partialConn struct {
+	net.Conn
+	n int
+}
+
+func (c *partialConn) Write(b []byte) (int, error) {
+	return min(c.n, len(b)), io.ErrShortWrite
+}
+
+func (c *partialConn) Close() error {
+	return nil
+}
+
+func TestTCPOutputResumesPartialWrite(t *testing.T) {
+	tests := []struct {
+		name    string
+		framing config.TCPFraming
+		written int
+		want    string
+	}{
+		{
+			name:    "cut at a frame boundary",
+			written: len("first\n"),
+			want:    "second\n",
+		},
+		{
+			name:    "cut in the middle of a frame",
+			written: len("first\nsec"),
+			want:    "second\n",
+		},
+		{
+			name:    "cut in the first frame",
+			written: len("fir"),
+			want:    "first\nsecond\n",
+		},
+		{
+			name:    "cut in an octet counted frame",
+			framing: config.TCPFramingOctetCounting,
+			written: len("5 first6 sec"),
+			want:    "6 second",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ln, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer ln.Close()
+
+			cfg := config.OutputConfig{
+				Type:    config.OutputTypeTCP,
+				Workers: 1,
+				Config: map[string]interface{}{
+					"address":         ln.Addr().String(),
+					"reconnect_delay": "10ms",
+					"framing":         string(tt.framing),
+				},
+			}
+
+			out, err := NewOutput(cfg, 0)
+			if err != nil {
+				t.Fatalf("NewOutput failed: %v", err)
+			}
+			defer out.Close()
+			first, err := ln.Accept()
+			if err != nil {
+				t.Fatal(err)
+			}
+			first.Close()
+
+			// The connection fails after some bytes, so the batch is resent
+			// from the first frame that was not fully written
+			tcp := out.(*tcpOutput)
+			tcp.conn.Close()
+			tcp.conn = &partialConn{n: tt.written}
+			tcp.dropped = make(chan struct{})
+
+			done := make(chan error, 1)
+			go func() {
+				done <- out.Write([]string{"first", "second"})
+			}()
+
+			conn, err := ln.Accept()
+			if err != nil {
+				t.Fatalf("Accept failed: %v", err)
+			}
+			defer conn.Close()
+			conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+			got := make([]byte, len(tt.want))
+			if _, err := io.ReadFull(conn, got); err != nil {
+				t.Fatalf("Failed to read after reconnecting: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("got %q after reconnecting, want %q", got, tt.want)
+			}
+			if err := <-done; err != nil {
+				t.Fatalf("Write failed: %v", err)
+			}
+		})
+	}
+}
+
+func TestTCPOutputCanceledWhileReconnecting(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.OutputConfig{
+		Type:    config.OutputTypeTCP,
+		Workers: 1,
+		Config: map[string]interface{}{
+			"address":         ln.Addr().String(),
+			"reconnect_delay": "10ms",
+		},
+	}
+
+	out, err := NewOutput(cfg, 0)
+	if err != nil {
+		t.Fatalf("NewOutput failed: %v", err)
+	}
+	defer out.Close()
+	conn, err := ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.Close()
+	ln.Close()
+	time.Sleep(50 * time.Millisecond)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- out.Write([]string{"never delivered"})
+	}()
+	time.Sleep(50 * time.Millisecond)
+	out.(Canceler).Cancel()
+
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Error("Expected error when writing to a canceled output, got nil")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Write did not return after Cancel")
+	}
+}